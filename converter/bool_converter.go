@@ -12,11 +12,27 @@ func ReadBool(dbValue interface{}, typ *sql.ColumnType, field reflect.StructFiel
 		(*result)[field.Name] = v
 		return nil
 	}
-	SetDefaultValue(result, field)
-	return nil
+	if _, hasDefault := field.Tag.Lookup("default"); dbValue == nil && !hasDefault && field.Type.Kind() == reflect.Ptr {
+		// a pointer field without default keeps NULL apart from false
+		(*result)[field.Name] = nil
+		return nil
+	}
+	return setDefault(result, field)
 }
 
 func WriteBool(fieldValue interface{}, typ *sql.ColumnType, field reflect.StructField, columnName string, result *map[string]interface{}) error {
+	if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Bool {
+		v, ok := fieldValue.(*bool)
+		if !ok {
+			return errors.New(fmt.Sprintf("can't convert %v: %v to bool", field.Name, fieldValue))
+		}
+		if v == nil {
+			(*result)[columnName] = nil
+			return nil
+		}
+		(*result)[columnName] = *v
+		return nil
+	}
 	if field.Type.Kind() == reflect.Bool {
 		v, ok := fieldValue.(bool)
 		if !ok {
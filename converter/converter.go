@@ -2,7 +2,25 @@ package converter
 
 import "reflect"
 
+// SetDefaultValue sets the value of the default tag or the zero value of the field for NULL
+//
+// an invalid default tag falls back to the zero value, it is reported as model problem when the model is built
 func SetDefaultValue(elem *map[string]interface{}, field reflect.StructField) {
+	_ = setDefault(elem, field)
+}
+
+// setDefault works like SetDefaultValue but returns the error of an invalid default tag
+func setDefault(elem *map[string]interface{}, field reflect.StructField) error {
+	v, ok, err := ParseDefault(field)
+	if ok && err == nil {
+		(*elem)[field.Name] = v
+		return nil
+	}
+	setZeroValue(elem, field)
+	return err
+}
+
+func setZeroValue(elem *map[string]interface{}, field reflect.StructField) {
 	switch field.Type.Kind() {
 	case reflect.Bool:
 		(*elem)[field.Name] = false
@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// timeLayouts are the formats of a default tag for time.Time fields
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// defaultKey identifies a parsed default, the result only depends on the field type and the tag
+type defaultKey struct {
+	typ reflect.Type
+	tag string
+}
+
+type parsedDefault struct {
+	value reflect.Value
+	err   error
+}
+
+// defaults caches the parsed default tags so NULL values don't parse them again
+var defaults sync.Map
+
+// ParseDefault parses the default tag of the field into the type of the field
+//
+// numbers, bools, durations like 1h30m, times as RFC 3339 or 2006-01-02 and json for structs, maps and slices
+// are supported. the second result is false when the field has no default tag
+func ParseDefault(field reflect.StructField) (interface{}, bool, error) {
+	tag, ok := field.Tag.Lookup("default")
+	if !ok {
+		return nil, false, nil
+	}
+	key := defaultKey{typ: field.Type, tag: tag}
+	cached, ok := defaults.Load(key)
+	if !ok {
+		res, err := parseDefault(tag, field.Type)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("invalid default %v: %v", tag, err.Error()))
+		}
+		cached, _ = defaults.LoadOrStore(key, parsedDefault{value: res, err: err})
+	}
+	parsed := cached.(parsedDefault)
+	if parsed.err != nil {
+		return nil, true, parsed.err
+	}
+	return CopyValue(parsed.value.Interface()), true, nil
+}
+
+// CopyValue returns a deep copy of the exported content of maps, slices, pointers and structs,
+// so a parsed default can be used for many rows without sharing it
+func CopyValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(value)).Interface()
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+	res := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			ptr := reflect.New(v.Type().Elem())
+			ptr.Elem().Set(copyValue(v.Elem()))
+			res.Set(ptr)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			res.Set(copyValue(v.Elem()))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			res.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			iter := v.MapRange()
+			for iter.Next() {
+				res.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			res.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				res.Index(i).Set(copyValue(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(copyValue(v.Index(i)))
+		}
+	case reflect.Struct:
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if len(v.Type().Field(i).PkgPath) < 1 {
+				res.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+	default:
+		res.Set(v)
+	}
+	return res
+}
+
+func parseDefault(tag string, t reflect.Type) (reflect.Value, error) {
+	res := reflect.New(t).Elem()
+	switch {
+	case t.Kind() == reflect.Ptr:
+		v, err := parseDefault(tag, t.Elem())
+		if err != nil {
+			return res, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	case t == durationType:
+		d, err := time.ParseDuration(tag)
+		if err != nil {
+			return res, err
+		}
+		res.SetInt(int64(d))
+		return res, nil
+	case t == timeType:
+		for _, layout := range timeLayouts {
+			if v, err := time.Parse(layout, tag); err == nil {
+				res.Set(reflect.ValueOf(v))
+				return res, nil
+			}
+		}
+		return res, errors.New("expect a time like 2006-01-02 or 2006-01-02T15:04:05Z07:00")
+	}
+	switch t.Kind() {
+	case reflect.String:
+		res.SetString(tag)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(tag)
+		if err != nil {
+			return res, err
+		}
+		res.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(tag, 10, t.Bits())
+		if err != nil {
+			return res, err
+		}
+		res.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(tag, 10, t.Bits())
+		if err != nil {
+			return res, err
+		}
+		res.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(tag, t.Bits())
+		if err != nil {
+			return res, err
+		}
+		res.SetFloat(f)
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		if err := json.Unmarshal([]byte(tag), res.Addr().Interface()); err != nil {
+			return res, err
+		}
+	default:
+		return res, errors.New(fmt.Sprintf("default is not supported for %v", t))
+	}
+	return res, nil
+}
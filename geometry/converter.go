@@ -0,0 +1,160 @@
+package geometry
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/nodejayes/qsm/query"
+	"reflect"
+)
+
+// templates of the dbread and dbwrite tags for the geometry converters
+const (
+	// GeoJSONRead selects the geometry as GeoJSON for the geoJsonRead converter
+	GeoJSONRead = "st_asgeojson($column, 15, 2)"
+	// EWKBRead selects the geometry as EWKB for the wkbRead converter
+	EWKBRead = "st_asewkb($column)"
+	// GeoJSONWrite creates the geometry from the bound GeoJSON of the geoJsonWrite converter
+	GeoJSONWrite = "st_geomfromgeojson($value)"
+	// EWKBWrite creates the geometry from the bound EWKB of the ewkbWrite converter
+	EWKBWrite = "st_geomfromewkb($value)"
+)
+
+var geometryType = reflect.TypeOf((*Geometry)(nil)).Elem()
+
+// Register registers the converters geoJsonRead, geoJsonWrite, wkbRead and ewkbWrite at the api,
+// reads geometry columns without read converter from their hex EWKB and writes geometry fields
+// without write converter as hex EWKB
+//
+//	Field geometry.Polygon `column:"geom" dbread:"st_asgeojson($column, 15, 2)" read:"geoJsonRead" dbwrite:"st_geomfromgeojson($value)" write:"geoJsonWrite"`
+func Register(api *query.Api) {
+	api.RegisterTypedConverter("geoJsonRead", query.ConverterFunc(ReadGeoJSON))
+	api.RegisterTypedConverter("geoJsonWrite", query.ConverterFunc(WriteGeoJSON))
+	api.RegisterTypedConverter("wkbRead", query.ConverterFunc(ReadWKB))
+	api.RegisterTypedConverter("ewkbWrite", query.ConverterFunc(WriteEWKB))
+	api.RegisterDbTypeConverter("geometry", query.ConverterFunc(ReadWKB))
+	registerWrite[Geometry](api)
+	registerWrite[Point](api)
+	registerWrite[*Point](api)
+	registerWrite[LineString](api)
+	registerWrite[*LineString](api)
+	registerWrite[Polygon](api)
+	registerWrite[*Polygon](api)
+	registerWrite[MultiPoint](api)
+	registerWrite[*MultiPoint](api)
+	registerWrite[MultiLineString](api)
+	registerWrite[*MultiLineString](api)
+	registerWrite[MultiPolygon](api)
+	registerWrite[*MultiPolygon](api)
+	registerWrite[GeometryCollection](api)
+	registerWrite[*GeometryCollection](api)
+}
+
+// registerWrite writes the fields of type T as hex EWKB which postgis casts to geometry without dbwrite tag
+func registerWrite[T any](api *query.Api) {
+	query.RegisterTypeConverter[T](api, nil, func(value T, c query.ConverterContext) (interface{}, error) {
+		res, err := WriteEWKB(value, c)
+		if data, ok := res.([]byte); ok {
+			return hex.EncodeToString(data), err
+		}
+		return res, err
+	})
+}
+
+// ReadGeoJSON reads GeoJSON into a geometry field, string fields get the GeoJSON text
+func ReadGeoJSON(value interface{}, c query.ConverterContext) (interface{}, error) {
+	return readGeometry(value, c, func(text []byte) (Geometry, error) {
+		return ParseGeoJSON(text)
+	})
+}
+
+// ReadWKB reads binary or hex encoded WKB and EWKB into a geometry field, string fields get the hex text
+func ReadWKB(value interface{}, c query.ConverterContext) (interface{}, error) {
+	return readGeometry(value, c, func(data []byte) (Geometry, error) {
+		if len(data) > 0 && (data[0] == 0 || data[0] == 1) {
+			return ParseWKB(data)
+		}
+		return ParseHex(string(data))
+	})
+}
+
+// WriteGeoJSON writes a geometry as GeoJSON text, use it with the GeoJSONWrite template in the dbwrite tag
+func WriteGeoJSON(value interface{}, c query.ConverterContext) (interface{}, error) {
+	g, text, err := writeGeometry(value)
+	if err != nil || g == nil {
+		return text, err
+	}
+	res, err := GeoJSON(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(res), nil
+}
+
+// WriteEWKB writes a geometry as EWKB, use it with the EWKBWrite template in the dbwrite tag
+func WriteEWKB(value interface{}, c query.ConverterContext) (interface{}, error) {
+	g, text, err := writeGeometry(value)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		if text != nil {
+			return nil, errors.New("can't write text as EWKB, use a geometry field")
+		}
+		return nil, nil
+	}
+	return EWKB(g)
+}
+
+func readGeometry(value interface{}, c query.ConverterContext, parse func(data []byte) (Geometry, error)) (interface{}, error) {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []uint8:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, errors.New(fmt.Sprintf("unexpected geometry value of type %T", value))
+	}
+	t := c.Field.Type
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(string(data)).Convert(t).Interface(), nil
+	}
+	g, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	gv := reflect.ValueOf(g)
+	switch {
+	case t == geometryType || gv.Type() == t:
+		return g, nil
+	case t.Kind() == reflect.Ptr && gv.Type() == t.Elem():
+		res := reflect.New(t.Elem())
+		res.Elem().Set(gv)
+		return res.Interface(), nil
+	}
+	return nil, errors.New(fmt.Sprintf("can't store %v in field of type %v", g.GeometryType(), t))
+}
+
+// writeGeometry returns the geometry of the field value, text is returned as it is
+func writeGeometry(value interface{}) (Geometry, interface{}, error) {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil, nil
+	}
+	if g, ok := v.Interface().(Geometry); ok {
+		return g, nil, nil
+	}
+	if v.Kind() == reflect.String {
+		return nil, v.String(), nil
+	}
+	return nil, nil, errors.New(fmt.Sprintf("can't write %T as geometry", value))
+}
@@ -0,0 +1,188 @@
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type geoJSON struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates,omitempty"`
+	Geometries  []json.RawMessage `json:"geometries,omitempty"`
+	CRS         *geoJSONCRS       `json:"crs,omitempty"`
+}
+
+// geoJSONCRS is the named crs postgis writes with st_asgeojson like EPSG:4326
+type geoJSONCRS struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Name string `json:"name"`
+	} `json:"properties"`
+}
+
+// ParseGeoJSON reads a GeoJSON geometry, the srid is taken from a named crs and is 0 without crs
+func ParseGeoJSON(data []byte) (Geometry, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	srid := 0
+	if doc.CRS != nil {
+		name := doc.CRS.Properties.Name
+		s, err := strconv.Atoi(name[strings.LastIndex(name, ":")+1:])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("unknown crs %v", name))
+		}
+		srid = s
+	}
+	g, err := doc.geometry()
+	if err != nil {
+		return nil, err
+	}
+	return withSRID(g, srid), nil
+}
+
+func (ctx *geoJSON) geometry() (Geometry, error) {
+	var err error
+	switch ctx.Type {
+	case "Point":
+		var c []float64
+		if err = json.Unmarshal(ctx.Coordinates, &c); err != nil {
+			return nil, err
+		}
+		res := Point{}
+		res.Coord, err = toCoord(c)
+		return res, err
+	case "LineString", "MultiPoint":
+		var c [][]float64
+		if err = json.Unmarshal(ctx.Coordinates, &c); err != nil {
+			return nil, err
+		}
+		coords, err := toCoords(c)
+		if ctx.Type == "LineString" {
+			return LineString{Coords: coords}, err
+		}
+		return MultiPoint{Coords: coords}, err
+	case "Polygon", "MultiLineString":
+		var c [][][]float64
+		if err = json.Unmarshal(ctx.Coordinates, &c); err != nil {
+			return nil, err
+		}
+		rings, err := toRings(c)
+		if ctx.Type == "Polygon" {
+			return Polygon{Rings: rings}, err
+		}
+		return MultiLineString{Lines: rings}, err
+	case "MultiPolygon":
+		var c [][][][]float64
+		if err = json.Unmarshal(ctx.Coordinates, &c); err != nil {
+			return nil, err
+		}
+		res := MultiPolygon{Polygons: make([][][]Coord, len(c))}
+		for idx := range c {
+			if res.Polygons[idx], err = toRings(c[idx]); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case "GeometryCollection":
+		res := GeometryCollection{Geometries: make([]Geometry, len(ctx.Geometries))}
+		for idx, raw := range ctx.Geometries {
+			var child geoJSON
+			if err = json.Unmarshal(raw, &child); err != nil {
+				return nil, err
+			}
+			if res.Geometries[idx], err = child.geometry(); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	return nil, errors.New(fmt.Sprintf("unknown GeoJSON geometry type %v", ctx.Type))
+}
+
+func toCoord(c []float64) (Coord, error) {
+	if len(c) != 2 {
+		return Coord{}, errors.New(fmt.Sprintf("expect a 2d coordinate but got %v values", len(c)))
+	}
+	return Coord{c[0], c[1]}, nil
+}
+
+func toCoords(c [][]float64) ([]Coord, error) {
+	var err error
+	res := make([]Coord, len(c))
+	for idx := range c {
+		if res[idx], err = toCoord(c[idx]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func toRings(c [][][]float64) ([][]Coord, error) {
+	var err error
+	res := make([][]Coord, len(c))
+	for idx := range c {
+		if res[idx], err = toCoords(c[idx]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// GeoJSON writes the geometry as GeoJSON, the srid is written as named crs when it is set
+func GeoJSON(g Geometry) ([]byte, error) {
+	doc, err := geoJSONDocument(g)
+	if err != nil {
+		return nil, err
+	}
+	if srid := g.GetSRID(); srid != 0 {
+		doc["crs"] = map[string]interface{}{
+			"type":       "name",
+			"properties": map[string]string{"name": fmt.Sprintf("EPSG:%v", srid)},
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func geoJSONDocument(g Geometry) (map[string]interface{}, error) {
+	doc := map[string]interface{}{"type": g.GeometryType()}
+	switch v := g.(type) {
+	case Point:
+		doc["coordinates"] = v.Coord
+	case LineString:
+		doc["coordinates"] = nonNil(v.Coords)
+	case Polygon:
+		doc["coordinates"] = v.Rings
+	case MultiPoint:
+		doc["coordinates"] = nonNil(v.Coords)
+	case MultiLineString:
+		doc["coordinates"] = v.Lines
+	case MultiPolygon:
+		doc["coordinates"] = v.Polygons
+	case GeometryCollection:
+		children := make([]interface{}, len(v.Geometries))
+		for idx, child := range v.Geometries {
+			c, err := geoJSONDocument(child)
+			if err != nil {
+				return nil, err
+			}
+			children[idx] = c
+		}
+		doc["geometries"] = children
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown geometry %T", g))
+	}
+	return doc, nil
+}
+
+// nonNil writes a nil coordinate list as empty GeoJSON array
+func nonNil(coords []Coord) []Coord {
+	if coords == nil {
+		return []Coord{}
+	}
+	return coords
+}
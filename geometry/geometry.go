@@ -0,0 +1,143 @@
+package geometry
+
+// Coord is a 2d coordinate with x (longitude) and y (latitude)
+type Coord [2]float64
+
+// Geometry is one of Point, LineString, Polygon, MultiPoint, MultiLineString, MultiPolygon and GeometryCollection
+type Geometry interface {
+	// GeometryType returns the name of the geometry type like Point or MultiPolygon
+	GeometryType() string
+	// GetSRID returns the spatial reference id, 0 when it is unknown
+	GetSRID() int
+}
+
+// wkb type codes of the geometries
+const (
+	pointCode              = 1
+	lineStringCode         = 2
+	polygonCode            = 3
+	multiPointCode         = 4
+	multiLineStringCode    = 5
+	multiPolygonCode       = 6
+	geometryCollectionCode = 7
+)
+
+type Point struct {
+	Coord Coord
+	SRID  int
+}
+
+func (ctx Point) GeometryType() string {
+	return "Point"
+}
+
+func (ctx Point) GetSRID() int {
+	return ctx.SRID
+}
+
+type LineString struct {
+	Coords []Coord
+	SRID   int
+}
+
+func (ctx LineString) GeometryType() string {
+	return "LineString"
+}
+
+func (ctx LineString) GetSRID() int {
+	return ctx.SRID
+}
+
+// Polygon the first ring is the exterior ring, the others are holes
+type Polygon struct {
+	Rings [][]Coord
+	SRID  int
+}
+
+func (ctx Polygon) GeometryType() string {
+	return "Polygon"
+}
+
+func (ctx Polygon) GetSRID() int {
+	return ctx.SRID
+}
+
+type MultiPoint struct {
+	Coords []Coord
+	SRID   int
+}
+
+func (ctx MultiPoint) GeometryType() string {
+	return "MultiPoint"
+}
+
+func (ctx MultiPoint) GetSRID() int {
+	return ctx.SRID
+}
+
+type MultiLineString struct {
+	Lines [][]Coord
+	SRID  int
+}
+
+func (ctx MultiLineString) GeometryType() string {
+	return "MultiLineString"
+}
+
+func (ctx MultiLineString) GetSRID() int {
+	return ctx.SRID
+}
+
+type MultiPolygon struct {
+	Polygons [][][]Coord
+	SRID     int
+}
+
+func (ctx MultiPolygon) GeometryType() string {
+	return "MultiPolygon"
+}
+
+func (ctx MultiPolygon) GetSRID() int {
+	return ctx.SRID
+}
+
+type GeometryCollection struct {
+	Geometries []Geometry
+	SRID       int
+}
+
+func (ctx GeometryCollection) GeometryType() string {
+	return "GeometryCollection"
+}
+
+func (ctx GeometryCollection) GetSRID() int {
+	return ctx.SRID
+}
+
+// withSRID returns the geometry with the srid set
+func withSRID(g Geometry, srid int) Geometry {
+	switch v := g.(type) {
+	case Point:
+		v.SRID = srid
+		return v
+	case LineString:
+		v.SRID = srid
+		return v
+	case Polygon:
+		v.SRID = srid
+		return v
+	case MultiPoint:
+		v.SRID = srid
+		return v
+	case MultiLineString:
+		v.SRID = srid
+		return v
+	case MultiPolygon:
+		v.SRID = srid
+		return v
+	case GeometryCollection:
+		v.SRID = srid
+		return v
+	}
+	return g
+}
@@ -0,0 +1,160 @@
+package geometry
+
+import (
+	"encoding/hex"
+	"github.com/nodejayes/qsm/query"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Field struct {
+	ID       int      `column:"id"`
+	Boundary Polygon  `column:"boundary" dbread:"st_asgeojson($column, 15, 2)" read:"geoJsonRead" dbwrite:"st_geomfromgeojson($value)" write:"geoJsonWrite"`
+	Center   *Point   `column:"center" dbread:"st_asewkb($column)" read:"wkbRead" dbwrite:"st_geomfromewkb($value)" write:"ewkbWrite"`
+	Shape    Geometry `column:"shape"`
+	Text     string   `column:"text" read:"geoJsonRead"`
+}
+
+func (ctx Field) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.fields"}, []string{"f"}
+}
+
+type Marker struct {
+	ID       int      `column:"id"`
+	Position Point    `column:"position"`
+	Area     *Polygon `column:"area"`
+	Shape    Geometry `column:"shape"`
+}
+
+func (ctx Marker) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.markers"}, []string{"m"}
+}
+
+var square = Polygon{Rings: [][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}, SRID: 4326}
+
+func TestWKBRoundTrip(t *testing.T) {
+	geometries := []Geometry{
+		Point{Coord: Coord{7.1, 51.2}, SRID: 4326},
+		LineString{Coords: []Coord{{0, 0}, {1, 1}}},
+		square,
+		MultiPoint{Coords: []Coord{{0, 0}, {2, 2}}, SRID: 25832},
+		MultiLineString{Lines: [][]Coord{{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}}}},
+		MultiPolygon{Polygons: [][][]Coord{square.Rings}, SRID: 4326},
+		GeometryCollection{Geometries: []Geometry{Point{Coord: Coord{1, 2}}, Polygon{Rings: square.Rings}}, SRID: 4326},
+	}
+	for _, g := range geometries {
+		data, err := EWKB(g)
+		if err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+			return
+		}
+		res, err := ParseHex(hex.EncodeToString(data))
+		if err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+			return
+		}
+		if !reflect.DeepEqual(res, g) {
+			t.Errorf("expect %v but was %v", g, res)
+		}
+		text, err := GeoJSON(g)
+		if err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+			return
+		}
+		if res, err = ParseGeoJSON(text); err != nil || !reflect.DeepEqual(res, g) {
+			t.Errorf("expect %v but was %v %v", g, res, err)
+		}
+	}
+}
+
+func TestParseWKB(t *testing.T) {
+	// POINT(1 2) as big endian wkb without srid
+	g, err := ParseHex("00000000013ff00000000000004000000000000000")
+	if err != nil || !reflect.DeepEqual(g, Point{Coord: Coord{1, 2}}) {
+		t.Errorf("expect POINT(1 2) but was %v %v", g, err)
+	}
+	// SRID=4326;POINT(1 2) as postgis returns geometry columns
+	g, err = ParseHex("0101000020E6100000000000000000F03F0000000000000040")
+	if err != nil || !reflect.DeepEqual(g, Point{Coord: Coord{1, 2}, SRID: 4326}) {
+		t.Errorf("expect SRID=4326;POINT(1 2) but was %v %v", g, err)
+	}
+	// POINT Z(1 2 3)
+	if _, err = ParseHex("01010000A0E6100000000000000000F03F00000000000000400000000000000840"); err == nil {
+		t.Errorf("expect an error for z coordinates")
+	}
+	if _, err = ParseHex("0101000020E6100000000000000000F03F"); err == nil {
+		t.Errorf("expect an error for truncated wkb")
+	}
+}
+
+func TestGeometryConverters(t *testing.T) {
+	q := query.New(nil)
+	Register(q)
+	if err := q.RegisterModel(Field{}); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	center := Point{Coord: Coord{0.5, 0.5}, SRID: 4326}
+	columns, err := q.WriteColumns(Field{ID: 1, Boundary: square, Center: &center, Shape: square}, 1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if columns[0].Name != "boundary" || columns[0].Expression != "st_geomfromgeojson($1)" ||
+		!strings.Contains(columns[0].Value.(string), `"EPSG:4326"`) {
+		t.Errorf("unexpected boundary column %v", columns[0])
+	}
+	if columns[1].Name != "center" || columns[1].Expression != "st_geomfromewkb($2)" {
+		t.Errorf("unexpected center column %v", columns[1])
+	}
+	if g, err := ParseWKB(columns[1].Value.([]byte)); err != nil || !reflect.DeepEqual(g, center) {
+		t.Errorf("expect the ewkb of the center but was %v %v", g, err)
+	}
+
+	c := query.ConverterContext{Field: reflect.StructField{Name: "Center", Type: reflect.TypeOf(&Point{})}}
+	v, err := ReadWKB([]byte("0101000020E6100000000000000000F03F0000000000000040"), c)
+	if err != nil || *v.(*Point) != (Point{Coord: Coord{1, 2}, SRID: 4326}) {
+		t.Errorf("expect a pointer to the point but was %v %v", v, err)
+	}
+	c.Field.Type = reflect.TypeOf(LineString{})
+	if _, err = ReadWKB([]byte("0101000020E6100000000000000000F03F0000000000000040"), c); err == nil {
+		t.Errorf("expect an error for a point in a LineString field")
+	}
+}
+
+func TestGeometryTypeConverters(t *testing.T) {
+	q := query.New(nil)
+	Register(q)
+	position := Point{Coord: Coord{1, 2}, SRID: 4326}
+	values, err := q.Values(Marker{ID: 1, Position: position, Shape: square})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["position"] != "0101000020e6100000000000000000f03f0000000000000040" {
+		t.Errorf("expect the hex ewkb of the position but was %v", values["position"])
+	}
+	if values["area"] != nil {
+		t.Errorf("expect a nil area but was %v", values["area"])
+	}
+	if g, err := ParseHex(values["shape"].(string)); err != nil || !reflect.DeepEqual(g, square) {
+		t.Errorf("expect the hex ewkb of the shape but was %v %v", g, err)
+	}
+
+	columns, err := q.WriteColumns(Marker{ID: 1, Position: position, Area: &square}, 1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	for _, column := range columns {
+		if column.Name != "area" {
+			continue
+		}
+		if g, err := ParseHex(column.Value.(string)); err != nil || !reflect.DeepEqual(g, square) {
+			t.Errorf("expect the hex ewkb of the area but was %v %v", g, err)
+		}
+		return
+	}
+	t.Errorf("expect an area column in %v", columns)
+}
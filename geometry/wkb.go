@@ -0,0 +1,310 @@
+package geometry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// flags of the ewkb type code
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// ParseWKB reads a geometry from WKB or the postgis EWKB with srid
+func ParseWKB(data []byte) (Geometry, error) {
+	r := &wkbReader{data: data}
+	g, err := r.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(data) {
+		return nil, errors.New(fmt.Sprintf("unexpected %v bytes after the geometry", len(data)-r.pos))
+	}
+	return g, nil
+}
+
+// ParseHex reads a geometry from hex encoded WKB or EWKB like postgis returns geometry columns
+func ParseHex(s string) (Geometry, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWKB(data)
+}
+
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (ctx *wkbReader) read(n int) ([]byte, error) {
+	if ctx.pos+n > len(ctx.data) {
+		return nil, errors.New("unexpected end of wkb")
+	}
+	res := ctx.data[ctx.pos : ctx.pos+n]
+	ctx.pos += n
+	return res, nil
+}
+
+func (ctx *wkbReader) uint32() (uint32, error) {
+	b, err := ctx.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.order.Uint32(b), nil
+}
+
+func (ctx *wkbReader) coord() (Coord, error) {
+	b, err := ctx.read(16)
+	if err != nil {
+		return Coord{}, err
+	}
+	return Coord{math.Float64frombits(ctx.order.Uint64(b)), math.Float64frombits(ctx.order.Uint64(b[8:]))}, nil
+}
+
+func (ctx *wkbReader) coords() ([]Coord, error) {
+	n, err := ctx.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n)*16 > len(ctx.data)-ctx.pos {
+		return nil, errors.New("unexpected end of wkb")
+	}
+	res := make([]Coord, n)
+	for idx := range res {
+		if res[idx], err = ctx.coord(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ctx *wkbReader) rings() ([][]Coord, error) {
+	n, err := ctx.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n)*4 > len(ctx.data)-ctx.pos {
+		return nil, errors.New("unexpected end of wkb")
+	}
+	res := make([][]Coord, n)
+	for idx := range res {
+		if res[idx], err = ctx.coords(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// header reads the byte order and type of the next geometry, srid is 0 without ewkb srid flag
+func (ctx *wkbReader) header() (uint32, int, error) {
+	b, err := ctx.read(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch b[0] {
+	case 0:
+		ctx.order = binary.BigEndian
+	case 1:
+		ctx.order = binary.LittleEndian
+	default:
+		return 0, 0, errors.New(fmt.Sprintf("invalid wkb byte order %v", b[0]))
+	}
+	typ, err := ctx.uint32()
+	if err != nil {
+		return 0, 0, err
+	}
+	srid := 0
+	if typ&ewkbSRID != 0 {
+		s, err := ctx.uint32()
+		if err != nil {
+			return 0, 0, err
+		}
+		srid = int(s)
+	}
+	if typ&(ewkbZ|ewkbM) != 0 || typ&0x0fffffff > 1000 {
+		return 0, 0, errors.New("geometries with z or m coordinates are not supported")
+	}
+	return typ & 0x0fffffff, srid, nil
+}
+
+// children reads the geometries of a multi geometry or collection
+func (ctx *wkbReader) children() ([]Geometry, error) {
+	n, err := ctx.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n)*5 > len(ctx.data)-ctx.pos {
+		return nil, errors.New("unexpected end of wkb")
+	}
+	res := make([]Geometry, n)
+	for idx := range res {
+		if res[idx], err = ctx.geometry(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ctx *wkbReader) geometry() (Geometry, error) {
+	typ, srid, err := ctx.header()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case pointCode:
+		c, err := ctx.coord()
+		return Point{Coord: c, SRID: srid}, err
+	case lineStringCode:
+		c, err := ctx.coords()
+		return LineString{Coords: c, SRID: srid}, err
+	case polygonCode:
+		r, err := ctx.rings()
+		return Polygon{Rings: r, SRID: srid}, err
+	case multiPointCode, multiLineStringCode, multiPolygonCode, geometryCollectionCode:
+		children, err := ctx.children()
+		if err != nil {
+			return nil, err
+		}
+		return multiGeometry(typ, children, srid)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown wkb geometry type %v", typ))
+}
+
+// multiGeometry builds a multi geometry from its children and checks their types
+func multiGeometry(typ uint32, children []Geometry, srid int) (Geometry, error) {
+	switch typ {
+	case multiPointCode:
+		res := MultiPoint{Coords: make([]Coord, len(children)), SRID: srid}
+		for idx, child := range children {
+			p, ok := child.(Point)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("unexpected %v in MultiPoint", child.GeometryType()))
+			}
+			res.Coords[idx] = p.Coord
+		}
+		return res, nil
+	case multiLineStringCode:
+		res := MultiLineString{Lines: make([][]Coord, len(children)), SRID: srid}
+		for idx, child := range children {
+			l, ok := child.(LineString)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("unexpected %v in MultiLineString", child.GeometryType()))
+			}
+			res.Lines[idx] = l.Coords
+		}
+		return res, nil
+	case multiPolygonCode:
+		res := MultiPolygon{Polygons: make([][][]Coord, len(children)), SRID: srid}
+		for idx, child := range children {
+			p, ok := child.(Polygon)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("unexpected %v in MultiPolygon", child.GeometryType()))
+			}
+			res.Polygons[idx] = p.Rings
+		}
+		return res, nil
+	}
+	for idx := range children {
+		children[idx] = withSRID(children[idx], 0)
+	}
+	return GeometryCollection{Geometries: children, SRID: srid}, nil
+}
+
+// EWKB writes the geometry as little endian EWKB, the srid is written when it is set
+func EWKB(g Geometry) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err := writeWKB(buf, g, g.GetSRID()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, typ uint32, srid int) {
+	buf.WriteByte(1)
+	if srid != 0 {
+		writeUint32(buf, typ|ewkbSRID)
+		writeUint32(buf, uint32(srid))
+		return
+	}
+	writeUint32(buf, typ)
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	buf.Write(b)
+}
+
+func writeCoords(buf *bytes.Buffer, coords ...Coord) {
+	b := make([]byte, 8)
+	for _, c := range coords {
+		for _, v := range c {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+			buf.Write(b)
+		}
+	}
+}
+
+func writeRings(buf *bytes.Buffer, rings [][]Coord) {
+	writeUint32(buf, uint32(len(rings)))
+	for _, ring := range rings {
+		writeUint32(buf, uint32(len(ring)))
+		writeCoords(buf, ring...)
+	}
+}
+
+func writeWKB(buf *bytes.Buffer, g Geometry, srid int) error {
+	switch v := g.(type) {
+	case Point:
+		writeHeader(buf, pointCode, srid)
+		writeCoords(buf, v.Coord)
+	case LineString:
+		writeHeader(buf, lineStringCode, srid)
+		writeUint32(buf, uint32(len(v.Coords)))
+		writeCoords(buf, v.Coords...)
+	case Polygon:
+		writeHeader(buf, polygonCode, srid)
+		writeRings(buf, v.Rings)
+	case MultiPoint:
+		writeHeader(buf, multiPointCode, srid)
+		writeUint32(buf, uint32(len(v.Coords)))
+		for _, c := range v.Coords {
+			writeHeader(buf, pointCode, 0)
+			writeCoords(buf, c)
+		}
+	case MultiLineString:
+		writeHeader(buf, multiLineStringCode, srid)
+		writeUint32(buf, uint32(len(v.Lines)))
+		for _, line := range v.Lines {
+			writeHeader(buf, lineStringCode, 0)
+			writeUint32(buf, uint32(len(line)))
+			writeCoords(buf, line...)
+		}
+	case MultiPolygon:
+		writeHeader(buf, multiPolygonCode, srid)
+		writeUint32(buf, uint32(len(v.Polygons)))
+		for _, rings := range v.Polygons {
+			writeHeader(buf, polygonCode, 0)
+			writeRings(buf, rings)
+		}
+	case GeometryCollection:
+		writeHeader(buf, geometryCollectionCode, srid)
+		writeUint32(buf, uint32(len(v.Geometries)))
+		for _, child := range v.Geometries {
+			if err := writeWKB(buf, child, 0); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown geometry %T", g))
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 module github.com/nodejayes/qsm
 
-go 1.15
+go 1.18
 
 require (
 	github.com/lib/pq v1.8.0
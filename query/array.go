@@ -0,0 +1,248 @@
+package query
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseArray parses the text of a postgres array like {1,2,NULL} or {{"a b",c},{d,e}}
+//
+// the result contains strings, nil for NULL elements and []interface{} for nested arrays
+func parseArray(text string) ([]interface{}, error) {
+	if strings.HasPrefix(text, "[") {
+		// an array with explicit bounds like [0:2]={1,2,3}
+		idx := strings.Index(text, "=")
+		if idx < 0 {
+			return nil, errors.New(fmt.Sprintf("invalid array %v", text))
+		}
+		text = text[idx+1:]
+	}
+	p := &arrayParser{text: text}
+	res, err := p.array()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.text) {
+		return nil, errors.New(fmt.Sprintf("unexpected %q at position %v of array %v", p.text[p.pos:], p.pos, text))
+	}
+	return res, nil
+}
+
+type arrayParser struct {
+	text string
+	pos  int
+}
+
+func (ctx *arrayParser) array() ([]interface{}, error) {
+	if ctx.pos >= len(ctx.text) || ctx.text[ctx.pos] != '{' {
+		return nil, errors.New(fmt.Sprintf("expect { at position %v of array %v", ctx.pos, ctx.text))
+	}
+	ctx.pos++
+	res := []interface{}{}
+	if ctx.pos < len(ctx.text) && ctx.text[ctx.pos] == '}' {
+		ctx.pos++
+		return res, nil
+	}
+	for {
+		if ctx.pos >= len(ctx.text) {
+			return nil, errors.New(fmt.Sprintf("unterminated array %v", ctx.text))
+		}
+		var elem interface{}
+		var err error
+		switch ctx.text[ctx.pos] {
+		case '{':
+			elem, err = ctx.array()
+		case '"':
+			elem, err = ctx.quoted()
+		default:
+			elem = ctx.unquoted()
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, elem)
+		if ctx.pos >= len(ctx.text) {
+			return nil, errors.New(fmt.Sprintf("unterminated array %v", ctx.text))
+		}
+		switch ctx.text[ctx.pos] {
+		case ',':
+			ctx.pos++
+		case '}':
+			ctx.pos++
+			return res, nil
+		default:
+			return nil, errors.New(fmt.Sprintf("unexpected %q at position %v of array %v", ctx.text[ctx.pos], ctx.pos, ctx.text))
+		}
+	}
+}
+
+func (ctx *arrayParser) quoted() (interface{}, error) {
+	ctx.pos++
+	buf := bytes.NewBuffer([]byte{})
+	for ctx.pos < len(ctx.text) {
+		c := ctx.text[ctx.pos]
+		ctx.pos++
+		switch c {
+		case '\\':
+			if ctx.pos < len(ctx.text) {
+				buf.WriteByte(ctx.text[ctx.pos])
+				ctx.pos++
+			}
+		case '"':
+			return buf.String(), nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("unterminated quoted element in array %v", ctx.text))
+}
+
+func (ctx *arrayParser) unquoted() interface{} {
+	start := ctx.pos
+	for ctx.pos < len(ctx.text) && ctx.text[ctx.pos] != ',' && ctx.text[ctx.pos] != '}' {
+		ctx.pos++
+	}
+	elem := strings.TrimSpace(ctx.text[start:ctx.pos])
+	if strings.EqualFold(elem, "NULL") {
+		return nil
+	}
+	return elem
+}
+
+// isArrayType checks if values of the type are read from and written to postgres array columns
+func isArrayType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// decodeArray parses the array text and converts the elements into the slice type t
+func decodeArray(value interface{}, t reflect.Type) (interface{}, error) {
+	text, err := textValue(value)
+	if err != nil {
+		return nil, err
+	}
+	elems, err := parseArray(text)
+	if err != nil {
+		return nil, err
+	}
+	res, err := arraySlice(elems, t)
+	if err != nil {
+		return nil, err
+	}
+	return res.Interface(), nil
+}
+
+func arraySlice(elems []interface{}, t reflect.Type) (reflect.Value, error) {
+	res := reflect.MakeSlice(t, len(elems), len(elems))
+	elemType := t.Elem()
+	for idx, elem := range elems {
+		switch v := elem.(type) {
+		case nil:
+			if elemType.Kind() != reflect.Ptr && !isArrayType(elemType) {
+				return reflect.Value{}, errors.New(fmt.Sprintf("NULL element %v can't be stored in %v, use a pointer element type", idx, elemType))
+			}
+		case []interface{}:
+			if !isArrayType(elemType) {
+				return reflect.Value{}, errors.New(fmt.Sprintf("nested array can't be stored in %v", elemType))
+			}
+			nested, err := arraySlice(v, elemType)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res.Index(idx).Set(nested)
+		default:
+			target := elemType
+			if target.Kind() == reflect.Ptr {
+				target = target.Elem()
+			}
+			converted, err := convertValue(v, target)
+			if err != nil {
+				return reflect.Value{}, errors.New(fmt.Sprintf("element %v: %v", idx, err.Error()))
+			}
+			cv := reflect.ValueOf(converted)
+			if elemType.Kind() == reflect.Ptr {
+				ptr := reflect.New(target)
+				ptr.Elem().Set(cv)
+				cv = ptr
+			}
+			res.Index(idx).Set(cv)
+		}
+	}
+	return res, nil
+}
+
+// encodeArray writes a slice as postgres array text, nil slices are written as NULL
+func encodeArray(v reflect.Value) (interface{}, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+	buf := bytes.NewBuffer([]byte{})
+	if err := writeArray(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+func writeArray(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteString("{")
+	for idx := 0; idx < v.Len(); idx++ {
+		if idx > 0 {
+			buf.WriteString(",")
+		}
+		elem := v.Index(idx)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				buf.WriteString("NULL")
+				continue
+			}
+			elem = elem.Elem()
+		}
+		if isArrayType(elem.Type()) {
+			if err := writeArray(buf, elem); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeArrayElement(buf, elem); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+func writeArrayElement(buf *bytes.Buffer, v reflect.Value) error {
+	if v.Type() == timeType {
+		writeQuoted(buf, v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		writeQuoted(buf, v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteString("t")
+		} else {
+			buf.WriteString("f")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	default:
+		return errors.New(fmt.Sprintf("can't write array element of type %v", v.Type()))
+	}
+	return nil
+}
+
+func writeQuoted(buf *bytes.Buffer, s string) {
+	buf.WriteString("\"")
+	buf.WriteString(strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s))
+	buf.WriteString("\"")
+}
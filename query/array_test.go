@@ -0,0 +1,99 @@
+package query
+
+import (
+	"database/sql/driver"
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Sensor struct {
+	ID       int         `column:"id"`
+	Values   []float64   `column:"values"`
+	Labels   []string    `column:"labels"`
+	Flags    []bool      `column:"flags"`
+	Readings []*int      `column:"readings"`
+	Grid     [][]int32   `column:"grid"`
+	Times    []time.Time `column:"times"`
+}
+
+func (ctx Sensor) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.sensors"}, []string{"s"}
+}
+
+func TestParseArray(t *testing.T) {
+	res, err := parseArray(`{{1,NULL},{"a \"b\"",c}}`)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := []interface{}{[]interface{}{"1", nil}, []interface{}{`a "b"`, "c"}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expect %v but was %v", expected, res)
+	}
+	res, err = parseArray("[0:1]={3,4}")
+	if err != nil || len(res) != 2 {
+		t.Errorf("expect two elements but was %v %v", res, err)
+	}
+	if _, err = parseArray("{1,2"); err == nil {
+		t.Errorf("expect an error for an unterminated array")
+	}
+}
+
+func TestSelectArrays(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"flags", "grid", "id", "labels", "readings", "times", "values"},
+		types:   []string{"_bool", "_int4", "int4", "_text", "_int4", "_timestamptz", "_float8"},
+		rows: [][]driver.Value{{
+			[]byte("{t,f}"), []byte("{{1,2},{3,4}}"), int64(1), []byte(`{plain,"with space","quote\"d",NULL}`),
+			[]byte("{5,NULL}"), []byte(`{"2020-01-02 03:04:05+00"}`), []byte("{1.5,2}"),
+		}},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Sensor{}, "", -1, -1)
+	if err == nil {
+		t.Errorf("expect an error for a NULL element in a []string")
+		return
+	}
+
+	q = newFakeApi(fakeResult{
+		columns: []string{"flags", "grid", "id", "labels", "readings", "times", "values"},
+		types:   []string{"_bool", "_int4", "int4", "_text", "_int4", "_timestamptz", "_float8"},
+		rows: [][]driver.Value{{
+			[]byte("{t,f}"), []byte("{{1,2},{3,4}}"), int64(1), []byte(`{plain,"with space","quote\"d"}`),
+			[]byte("{5,NULL}"), []byte(`{"2020-01-02 03:04:05+00"}`), []byte("{1.5,2}"),
+		}},
+	})
+	defer q.connection.Disconnect()
+	tmp, err = q.Select(Sensor{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Sensor
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	s := res[0]
+	if !reflect.DeepEqual(s.Values, []float64{1.5, 2}) || !reflect.DeepEqual(s.Labels, []string{"plain", "with space", `quote"d`}) ||
+		!reflect.DeepEqual(s.Flags, []bool{true, false}) || !reflect.DeepEqual(s.Grid, [][]int32{{1, 2}, {3, 4}}) ||
+		len(s.Readings) != 2 || *s.Readings[0] != 5 || s.Readings[1] != nil ||
+		!s.Times[0].Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected result %v", s)
+	}
+
+	values, err := q.Values(s)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["labels"] != `{"plain","with space","quote\"d"}` || values["grid"] != "{{1,2},{3,4}}" ||
+		values["readings"] != "{5,NULL}" || values["flags"] != "{t,f}" || values["values"] != "{1.5,2}" {
+		t.Errorf("unexpected values %v", values)
+	}
+	if values, _ = q.Values(Sensor{}); values["labels"] != nil {
+		t.Errorf("expect a nil slice to be written as NULL but was %v", values["labels"])
+	}
+}
@@ -0,0 +1,303 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nodejayes/qsm/converter"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConversionError is returned when a database value can't be converted into the type of the field
+type ConversionError struct {
+	Field  string
+	Column string
+	DbType string
+	GoType string
+	Value  interface{}
+	Err    error
+}
+
+func (ctx *ConversionError) Error() string {
+	return fmt.Sprintf("can't convert value %v of column %v (%v) into field %v (%v): %v", ctx.Value, ctx.Column, ctx.DbType, ctx.Field, ctx.GoType, ctx.Err.Error())
+}
+
+func (ctx *ConversionError) Unwrap() error {
+	return ctx.Err
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayouts are the text formats of date and time values that are parsed into time.Time fields
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// errUnsupported is returned by convertValue when the matrix has no conversion for the field type
+var errUnsupported = errors.New("conversion not supported")
+
+// convertDbValue converts a scanned database value into the type of the field when no converter is defined
+//
+// the second result is false when there is no conversion for the field type, the value is left out of the row then
+func convertDbValue(value interface{}, dbType string, info *ModelInfo, columnName string) (interface{}, bool, error) {
+	t := info.field.Type
+	if value == nil {
+		if info.hasDefault {
+			// the default is parsed once with the model, every row gets its own copy
+			return converter.CopyValue(info.defaultValue), true, nil
+		}
+		switch {
+		case nullable(t):
+			return nil, true, nil
+		case info.Null == NullNotAllowed:
+			return nil, false, &ConversionError{Field: info.FieldName, Column: columnName, DbType: dbType, GoType: t.String(), Err: errNull}
+		}
+		return reflect.Zero(t).Interface(), true, nil
+	}
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	var res interface{}
+	var err error
+	if readsJSON(info, dbType, value) {
+		res, err = decodeJSON(value, t)
+	} else {
+		res, err = convertValue(value, t)
+	}
+	if err == errUnsupported {
+		if _, ok := value.([]uint8); ok {
+			return nil, false, nil
+		}
+		return value, true, nil
+	}
+	if err != nil {
+		return nil, false, &ConversionError{
+			Field:  info.FieldName,
+			Column: columnName,
+			DbType: dbType,
+			GoType: info.field.Type.String(),
+			Value:  printableValue(value),
+			Err:    err,
+		}
+	}
+	if isPtr {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(reflect.ValueOf(res))
+		return ptr.Interface(), true, nil
+	}
+	return res, true, nil
+}
+
+// convertValue converts value into the Go type t with overflow checks
+func convertValue(value interface{}, t reflect.Type) (interface{}, error) {
+	if t == timeType {
+		return convertTime(value)
+	}
+	target := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		s, err := textValue(value)
+		if err != nil {
+			return nil, err
+		}
+		target.SetString(s)
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			target.SetBool(v)
+		case []uint8, string:
+			s, _ := textValue(v)
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, err
+			}
+			target.SetBool(b)
+		default:
+			return nil, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := intValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if target.OverflowInt(i) {
+			return nil, errors.New(fmt.Sprintf("%v overflows %v", i, t))
+		}
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := uintValue(value, t)
+		if err != nil {
+			return nil, err
+		}
+		if target.OverflowUint(i) {
+			return nil, errors.New(fmt.Sprintf("%v overflows %v", i, t))
+		}
+		target.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := floatValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if target.OverflowFloat(f) {
+			return nil, errors.New(fmt.Sprintf("%v overflows %v", f, t))
+		}
+		target.SetFloat(f)
+	case reflect.Slice:
+		if isArrayType(t) {
+			return decodeArray(value, t)
+		}
+		switch v := value.(type) {
+		case []uint8:
+			target.SetBytes(append([]byte{}, v...))
+		case string:
+			target.SetBytes([]byte(v))
+		default:
+			return nil, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+		}
+	default:
+		return nil, errUnsupported
+	}
+	return target.Interface(), nil
+}
+
+// textValue returns the text of a scanned value
+func textValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []uint8:
+		return string(v), nil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return "", errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+}
+
+// zeroFraction matches the zero fraction of numeric text like 12.00
+var zeroFraction = regexp.MustCompile(`\.0+$`)
+
+// maxExactFloat is 2^53, from there on floats can't represent every integer
+const maxExactFloat = 1 << 53
+
+// intValue returns a scanned value as int64, numeric text and floats must not have a fractional part
+func intValue(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return floatToInt(v)
+	case []uint8, string:
+		s, _ := textValue(v)
+		s = zeroFraction.ReplaceAllString(strings.TrimSpace(s), "")
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err == nil || errors.Is(err, strconv.ErrRange) {
+			return i, err
+		}
+		f, floatErr := strconv.ParseFloat(s, 64)
+		if floatErr != nil {
+			return 0, err
+		}
+		return floatToInt(f)
+	}
+	return 0, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+}
+
+// uintValue returns a scanned value as uint64 for the unsigned type t, negative values are rejected
+func uintValue(value interface{}, t reflect.Type) (uint64, error) {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return 0, errors.New(fmt.Sprintf("%v overflows %v", v, t))
+		}
+		return uint64(v), nil
+	case float64:
+		if v < 0 {
+			return 0, errors.New(fmt.Sprintf("%v overflows %v", v, t))
+		}
+		i, err := floatToInt(v)
+		return uint64(i), err
+	case []uint8, string:
+		s, _ := textValue(v)
+		s = zeroFraction.ReplaceAllString(strings.TrimSpace(s), "")
+		if strings.HasPrefix(s, "-") {
+			return 0, errors.New(fmt.Sprintf("%v overflows %v", s, t))
+		}
+		i, err := strconv.ParseUint(s, 10, 64)
+		if err == nil || errors.Is(err, strconv.ErrRange) {
+			return i, err
+		}
+		f, floatErr := strconv.ParseFloat(s, 64)
+		if floatErr != nil {
+			return 0, err
+		}
+		n, err := floatToInt(f)
+		return uint64(n), err
+	}
+	return 0, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+}
+
+// floatToInt converts an integral float, floats from 2^53 on are rejected because they may have lost precision
+func floatToInt(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, errors.New(fmt.Sprintf("%v has a fractional part", f))
+	}
+	if math.Abs(f) >= maxExactFloat {
+		return 0, errors.New(fmt.Sprintf("%v can't be represented exactly as integer", f))
+	}
+	return int64(f), nil
+}
+
+// floatValue returns a scanned value as float64
+func floatValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case []uint8, string:
+		s, _ := textValue(v)
+		return strconv.ParseFloat(s, 64)
+	}
+	return 0, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+}
+
+// convertTime parses date and time text into time.Time
+func convertTime(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []uint8, string:
+		s, _ := textValue(v)
+		for _, layout := range timeLayouts {
+			if res, err := time.Parse(layout, s); err == nil {
+				return res, nil
+			}
+		}
+		return nil, errors.New(fmt.Sprintf("unknown time format %v", s))
+	}
+	return nil, errors.New(fmt.Sprintf("unexpected database value of type %T", value))
+}
+
+// printableValue returns bytes as text so they are readable in error messages
+func printableValue(value interface{}) interface{} {
+	if v, ok := value.([]uint8); ok {
+		return strings.ToValidUTF8(string(v), "?")
+	}
+	return value
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Measurement struct {
+	ID       int32     `column:"id"`
+	Count    uint16    `column:"count"`
+	Amount   float64   `column:"amount"`
+	Ratio    float32   `column:"ratio"`
+	Key      string    `column:"key"`
+	Valid    bool      `column:"valid"`
+	Raw      []byte    `column:"raw"`
+	Measured time.Time `column:"measured"`
+}
+
+func (ctx Measurement) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.measurements"}, []string{"m"}
+}
+
+func TestSelectDbTypeConversion(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"amount", "count", "id", "key", "measured", "raw", "ratio", "valid"},
+		types:   []string{"numeric", "int8", "int8", "uuid", "date", "bytea", "float4", "text"},
+		rows: [][]driver.Value{
+			{[]byte("12.75"), int64(7), int64(42), []byte("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"), []byte("2020-05-01"), []byte{1, 2}, float64(0.5), []byte("t")},
+		},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Measurement{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Measurement
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	m := res[0]
+	if m.ID != 42 || m.Count != 7 || m.Amount != 12.75 || m.Ratio != 0.5 || !m.Valid ||
+		m.Key != "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11" || len(m.Raw) != 2 ||
+		!m.Measured.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected result %v", m)
+	}
+}
+
+func TestSelectDbTypeConversionOverflow(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"count", "id"},
+		types:   []string{"int8", "int8"},
+		rows:    [][]driver.Value{{int64(-1), int64(1) << 40}},
+	})
+	defer q.connection.Disconnect()
+	_, err := q.Select(Measurement{}, "", -1, -1)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Errorf("expect a ConversionError but was %v", err)
+		return
+	}
+	if convErr.Field != "Count" || convErr.Column != "count" || convErr.DbType != "INT8" || convErr.GoType != "uint16" {
+		t.Errorf("unexpected error %v", convErr)
+	}
+	if !strings.Contains(err.Error(), "-1 overflows uint16") {
+		t.Errorf("expect the overflow in the message but was %v", err.Error())
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	v, err := convertValue([]byte("12.00"), reflect.TypeOf(int8(0)))
+	if err != nil || v != int8(12) {
+		t.Errorf("expect 12 but was %v %v", v, err)
+	}
+	if _, err = convertValue([]byte("12.5"), reflect.TypeOf(int8(0))); err == nil {
+		t.Errorf("expect an error for a fractional part")
+	}
+	if _, err = convertValue(int64(300), reflect.TypeOf(int8(0))); err == nil {
+		t.Errorf("expect an error for an overflow")
+	}
+	if _, err = convertValue(float64(1e40), reflect.TypeOf(float32(0))); err == nil {
+		t.Errorf("expect an error for a float32 overflow")
+	}
+	v, err = convertValue([]byte("18446744073709551615"), reflect.TypeOf(uint64(0)))
+	if err != nil || v != uint64(18446744073709551615) {
+		t.Errorf("expect the max uint64 but was %v %v", v, err)
+	}
+	v, err = convertValue([]byte("9007199254740993.00"), reflect.TypeOf(int64(0)))
+	if err != nil || v != int64(9007199254740993) {
+		t.Errorf("expect 9007199254740993 but was %v %v", v, err)
+	}
+	if v, err = convertValue([]byte("9.007199254740993e15"), reflect.TypeOf(int64(0))); err == nil {
+		t.Errorf("expect an error for a float that can't be represented exactly but was %v", v)
+	}
+	if _, err = convertValue([]byte("-1"), reflect.TypeOf(uint8(0))); err == nil {
+		t.Errorf("expect an error for a negative unsigned value")
+	}
+}
@@ -0,0 +1,172 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// converterCall is one stage of a converter chain like round(2) in tt.height->round(2)
+type converterCall struct {
+	Name string
+	Args []string
+}
+
+func (ctx converterCall) String() string {
+	if len(ctx.Args) < 1 {
+		return ctx.Name
+	}
+	return fmt.Sprintf("%v(%v)", ctx.Name, strings.Join(ctx.Args, ", "))
+}
+
+// parseConverterChain parses the stages of a converter chain that are separated by sep
+//
+// each stage is a name with optional arguments in parentheses, arguments are separated by commas
+// and can be quoted with single quotes to contain commas or parentheses
+func parseConverterChain(chain, sep string) ([]converterCall, error) {
+	var res []converterCall
+	for _, stage := range splitOutside(chain, sep) {
+		stage = strings.TrimSpace(stage)
+		if len(stage) < 1 {
+			return nil, errors.New(fmt.Sprintf("empty converter in %v", chain))
+		}
+		call := converterCall{Name: stage}
+		if idx := strings.Index(stage, "("); idx > -1 {
+			if !strings.HasSuffix(stage, ")") {
+				return nil, errors.New(fmt.Sprintf("missing ) in converter %v", stage))
+			}
+			call.Name = strings.TrimSpace(stage[:idx])
+			args := strings.TrimSpace(stage[idx+1 : len(stage)-1])
+			if len(args) > 0 {
+				for _, arg := range splitOutside(args, ",") {
+					call.Args = append(call.Args, strings.TrimSpace(arg))
+				}
+			}
+		}
+		if len(call.Name) < 1 {
+			return nil, errors.New(fmt.Sprintf("missing converter name in %v", stage))
+		}
+		res = append(res, call)
+	}
+	return res, nil
+}
+
+// splitColumnConverter splits a column tag at the first -> that starts a converter chain
+//
+// postgres json operators like ->>, ->'key' or ->0 stay in the column expression
+func splitColumnConverter(column string) (string, string) {
+	parts := splitOutside(column, "->")
+	for idx := 1; idx < len(parts); idx++ {
+		if isConverterChain(parts[idx:]) {
+			return strings.Join(parts[:idx], "->"), strings.Join(parts[idx:], "->")
+		}
+	}
+	return column, ""
+}
+
+// columnExpression returns the column tag without its column converters
+func columnExpression(column string) string {
+	column, _ = splitColumnConverter(column)
+	return column
+}
+
+// isConverterChain checks that every stage starts with a converter name, arguments are checked by parseConverterChain
+func isConverterChain(stages []string) bool {
+	for _, stage := range stages {
+		stage = strings.TrimSpace(stage)
+		if idx := strings.Index(stage, "("); idx > -1 {
+			stage = strings.TrimSpace(stage[:idx])
+		}
+		if len(stage) < 1 || stage[0] == '"' || !isIdentifier(stage) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitOutside splits value at sep, separators inside of quotes or parentheses are ignored
+func splitOutside(value, sep string) []string {
+	var res []string
+	depth := 0
+	quoted := false
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\'':
+			quoted = !quoted
+		case quoted:
+			continue
+		case value[i] == '(':
+			depth++
+		case value[i] == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(value[i:], sep):
+			res = append(res, value[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(res, value[start:])
+}
+
+var columnConverterParameter = regexp.MustCompile(`\$([0-9]+)`)
+
+// applyColumnConverter replaces $column in the definition with the column expression
+// and $1, $2 ... with the arguments as typed sql literals
+func applyColumnConverter(definition, column string, args []string) (string, error) {
+	expected := 0
+	for _, match := range columnConverterParameter.FindAllStringSubmatch(definition, -1) {
+		n, _ := strconv.Atoi(match[1])
+		if n > expected {
+			expected = n
+		}
+	}
+	if len(args) != expected {
+		return "", errors.New(fmt.Sprintf("expects %v arguments but got %v", expected, len(args)))
+	}
+	res := columnConverterParameter.ReplaceAllStringFunc(definition, func(placeholder string) string {
+		n, _ := strconv.Atoi(placeholder[1:])
+		return sqlLiteral(args[n-1])
+	})
+	return strings.ReplaceAll(res, "$column", column), nil
+}
+
+// sqlLiteral converts a converter argument to a sql literal, numbers, booleans and null are kept,
+// everything else is written as quoted text
+func sqlLiteral(arg string) string {
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return arg
+	}
+	switch strings.ToLower(arg) {
+	case "true", "false", "null":
+		return strings.ToLower(arg)
+	}
+	if len(arg) > 1 && strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'") {
+		arg = strings.ReplaceAll(arg[1:len(arg)-1], "''", "'")
+	}
+	return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+}
+
+// columnExpression applies the column converters of the field to the qualified column
+func (ctx *Api) columnExpression(info *ModelInfo, column string) (string, error) {
+	if len(info.ColumnConverter) < 1 {
+		return column, nil
+	}
+	calls, err := parseConverterChain(info.ColumnConverter, "->")
+	if err != nil {
+		return "", err
+	}
+	for _, call := range calls {
+		definition, ok := ctx.columnConverter[call.Name]
+		if !ok {
+			return "", errors.New(fmt.Sprintf("column converter %v is not registered", call.Name))
+		}
+		column, err = applyColumnConverter(definition, column, call.Args)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("column converter %v %v", call.Name, err.Error()))
+		}
+	}
+	return column, nil
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+type ConvertedColumns struct {
+	Height float64 `column:"tt.height->round(2)"`
+	Geom   string  `column:"tt.geom->transform(4326)->geojson"`
+	Name   string  `column:"tt.name->trim->lower"`
+	Label  string  `column:"tt.label->coalesce('n/a, none')"`
+}
+
+type JsonColumns struct {
+	Hello string `column:"tt.dyn->>'hello'" alias:"hello"`
+	First string `column:"tt.dyn->'list'->0->trim" alias:"first"`
+}
+
+func (ctx JsonColumns) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.test_types"}, []string{"tt"}
+}
+
+func (ctx ConvertedColumns) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.test_types"}, []string{"tt"}
+}
+
+func TestParseConverterChain(t *testing.T) {
+	calls, err := parseConverterChain("trim, nullToEmpty,decrypt(keyA, 'a,b')", ",")
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(calls) != 3 || calls[0].Name != "trim" || calls[1].Name != "nullToEmpty" ||
+		calls[2].Name != "decrypt" || len(calls[2].Args) != 2 || calls[2].Args[1] != "'a,b'" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	if _, err = parseConverterChain("round(2", "->"); err == nil {
+		t.Errorf("expect an error for a missing parenthesis")
+	}
+}
+
+func TestGenerateSelectColumnConverters(t *testing.T) {
+	q := New(nil)
+	q.RegisterColumnConvert("round", "round($column::numeric, $1)")
+	q.RegisterColumnConvert("transform", "st_transform($column, $1)")
+	q.RegisterColumnConvert("geojson", "st_asgeojson($column)")
+	q.RegisterColumnConvert("trim", "trim($column)")
+	q.RegisterColumnConvert("lower", "lower($column)")
+	q.RegisterColumnConvert("coalesce", "coalesce($column, $1)")
+	query, err := q.generateSelect(ConvertedColumns{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select st_asgeojson(st_transform(tt.geom, 4326)) as \"geom\", round(tt.height::numeric, 2) as \"height\", " +
+		"coalesce(tt.label, 'n/a, none') as \"label\", lower(trim(tt.name)) as \"name\" from public.test_types tt "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+	}
+
+	q.RegisterColumnConvert("round", "round($column::numeric, $1, $2)")
+	_, err = q.generateSelect(ConvertedColumns{}, "", -1, -1)
+	if err == nil || !strings.Contains(err.Error(), "column converter round expects 2 arguments but got 1") {
+		t.Errorf("expect an error for the wrong argument count but was %v", err)
+	}
+	q.RegisterColumnConvert("round", "round($column::numeric, $1)")
+	q.UnregisterColumnConvert("lower")
+	_, err = q.generateSelect(ConvertedColumns{}, "", -1, -1)
+	if err == nil || !strings.Contains(err.Error(), "column converter lower is not registered") {
+		t.Errorf("expect an error for an unknown converter but was %v", err)
+	}
+}
+
+func TestGenerateSelectJsonOperatorColumns(t *testing.T) {
+	q := New(nil)
+	q.RegisterColumnConvert("trim", "trim($column)")
+	query, err := q.generateSelect(JsonColumns{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select trim(tt.dyn->'list'->0) as \"first\", tt.dyn->>'hello' as \"hello\" from public.test_types tt "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+	}
+}
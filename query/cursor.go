@@ -0,0 +1,101 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultCursorFetchSize is the number of rows SelectCursor fetches per round trip when nothing else is configured
+const DefaultCursorFetchSize = 1000
+
+var cursorCounter uint64
+
+type cursor struct {
+	tx        *sql.Tx
+	ownTx     bool
+	name      string
+	fetchSize int
+}
+
+// SetCursorFetchSize sets the number of rows SelectCursor fetches from the server per round trip
+func (ctx *Api) SetCursorFetchSize(fetchSize int) {
+	if fetchSize < 1 {
+		fetchSize = DefaultCursorFetchSize
+	}
+	ctx.cursorFetchSize = fetchSize
+}
+
+// SelectCursor runs the model select through a server side cursor and returns an iterator over the mapped rows
+//
+// the cursor lives in its own transaction or in the transaction of WithTx, only the configured fetch size of rows
+// is held in memory at once. the cursor is closed and its own transaction ended when the Rows are closed,
+// so always call Close or use ForEach
+func (ctx *Api) SelectCursor(c context.Context, target interface{}, where string, limit, offset int, args ...map[string]interface{}) (*Rows, error) {
+	query, err := ctx.generateSelect(target, where, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	query, values := bindArgs(query, args)
+
+	tx := ctx.tx
+	if tx == nil {
+		tx, err = ctx.db().BeginTx(c, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cur := &cursor{
+		tx:        tx,
+		ownTx:     ctx.tx == nil,
+		name:      fmt.Sprintf("qsm_cursor_%v", atomic.AddUint64(&cursorCounter, 1)),
+		fetchSize: ctx.cursorFetchSize,
+	}
+	_, err = tx.ExecContext(c, fmt.Sprintf("declare %v no scroll cursor for %v", cur.name, query), values...)
+	if err != nil {
+		_ = cur.close(c, false)
+		return nil, wrapLockError(err)
+	}
+	rows, err := cur.fetch(c)
+	if err != nil {
+		_ = cur.close(c, false)
+		return nil, wrapLockError(err)
+	}
+	res, err := ctx.newRows(c, target, rows)
+	if err != nil {
+		_ = cur.close(c, false)
+		return nil, err
+	}
+	res.cursor = cur
+	return res, nil
+}
+
+func (ctx *cursor) fetch(c context.Context) (*sql.Rows, error) {
+	return ctx.tx.QueryContext(c, fmt.Sprintf("fetch %v from %v", ctx.fetchSize, ctx.name))
+}
+
+// close closes the cursor and commits its own transaction, on failure or cancellation the transaction is rolled back
+//
+// a transaction of WithTx is left to the caller, only the cursor is closed
+func (ctx *cursor) close(c context.Context, commit bool) error {
+	if !ctx.ownTx {
+		if c.Err() != nil {
+			return nil
+		}
+		_, err := ctx.tx.ExecContext(c, fmt.Sprintf("close %v", ctx.name))
+		return err
+	}
+	if !commit || c.Err() != nil {
+		err := ctx.tx.Rollback()
+		if err == sql.ErrTxDone {
+			return nil
+		}
+		return err
+	}
+	if _, err := ctx.tx.ExecContext(c, fmt.Sprintf("close %v", ctx.name)); err != nil {
+		_ = ctx.tx.Rollback()
+		return err
+	}
+	return ctx.tx.Commit()
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApi_SelectCursor(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("a")}, {[]byte("b")}},
+	}, fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("c")}},
+	})
+	defer q.connection.Disconnect()
+	q.SetCursorFetchSize(2)
+	rows, err := q.SelectCursor(context.Background(), Db{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	count := 0
+	err = rows.ForEach(func(row map[string]interface{}) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if count != 3 {
+		t.Errorf("expect 3 rows but was %v", count)
+		return
+	}
+	queries := fakeQueries()
+	if len(queries) != 6 ||
+		queries[0] != "begin" ||
+		!strings.HasPrefix(queries[1], "declare qsm_cursor_") ||
+		!strings.HasPrefix(queries[2], "fetch 2 from qsm_cursor_") ||
+		!strings.HasPrefix(queries[3], "fetch 2 from qsm_cursor_") ||
+		!strings.HasPrefix(queries[4], "close qsm_cursor_") ||
+		queries[5] != "commit" {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
+
+func TestApi_SelectCursorStopEarly(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("a")}, {[]byte("b")}},
+	})
+	defer q.connection.Disconnect()
+	q.SetCursorFetchSize(2)
+	rows, err := q.SelectCursor(context.Background(), Db{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	stop := errors.New("stop")
+	err = rows.ForEach(func(row map[string]interface{}) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expect the error of the callback but was: %v", err)
+		return
+	}
+	queries := fakeQueries()
+	if len(queries) != 5 ||
+		!strings.HasPrefix(queries[3], "close qsm_cursor_") ||
+		queries[4] != "commit" {
+		t.Errorf("expect cursor to be closed and transaction ended but queries were %v", queries)
+	}
+}
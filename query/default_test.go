@@ -0,0 +1,110 @@
+package query
+
+import (
+	"database/sql/driver"
+	"github.com/mitchellh/mapstructure"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Settings struct {
+	ID       int               `column:"id"`
+	Retries  uint8             `column:"retries" default:"3"`
+	Ratio    float64           `column:"ratio" default:"0.5"`
+	Enabled  bool              `column:"enabled" read:"ReadBool" default:"true"`
+	Timeout  time.Duration     `column:"timeout" default:"1m30s"`
+	Since    time.Time         `column:"since" default:"2020-01-01"`
+	Options  DynStruct         `column:"options" default:"{\"hello\":\"default\"}"`
+	Labels   map[string]string `column:"labels" default:"{\"crop\":\"wheat\"}"`
+	Name     *string           `column:"name" default:"unknown"`
+	Archived *bool             `column:"archived" read:"ReadBool"`
+}
+
+func (ctx Settings) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.settings"}, []string{"s"}
+}
+
+type InvalidDefaults struct {
+	ID      int           `column:"id"`
+	Retries uint8         `column:"retries" default:"300"`
+	Timeout time.Duration `column:"timeout" default:"soon"`
+	Options DynStruct     `column:"options" default:"{"`
+}
+
+func (ctx InvalidDefaults) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.settings"}, []string{"s"}
+}
+
+func TestSelectDefault(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"archived", "enabled", "id", "labels", "name", "options", "ratio", "retries", "since", "timeout"},
+		types:   []string{"bool", "bool", "int4", "jsonb", "text", "jsonb", "float8", "int2", "date", "int8"},
+		rows:    [][]driver.Value{{nil, nil, int64(1), nil, nil, nil, nil, nil, nil, nil}},
+	})
+	defer q.connection.Disconnect()
+	if err := q.RegisterModel(Settings{}); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	tmp, err := q.Select(Settings{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Settings
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	s := res[0]
+	if s.Retries != 3 || s.Ratio != 0.5 || !s.Enabled || s.Timeout != 90*time.Second ||
+		!s.Since.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) || s.Options.Hello != "default" ||
+		s.Labels["crop"] != "wheat" || s.Name == nil || *s.Name != "unknown" || s.Archived != nil {
+		t.Errorf("expect the defaults for NULL but was %v", s)
+	}
+}
+
+func TestRegisterModelInvalidDefault(t *testing.T) {
+	q := New(nil)
+	err := q.RegisterModel(InvalidDefaults{})
+	if err == nil {
+		t.Errorf("expect an error for the invalid defaults")
+		return
+	}
+	for _, field := range []string{"Retries", "Timeout", "Options"} {
+		if !strings.Contains(err.Error(), "field "+field+" tag default: invalid default") {
+			t.Errorf("expect a problem for the default of %v but was %v", field, err.Error())
+		}
+	}
+}
+
+func TestSelectDefaultCopies(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"archived", "enabled", "id", "labels", "name", "options", "ratio", "retries", "since", "timeout"},
+		types:   []string{"bool", "bool", "int4", "jsonb", "text", "jsonb", "float8", "int2", "date", "int8"},
+		rows: [][]driver.Value{
+			{nil, nil, int64(1), nil, nil, nil, nil, nil, nil, nil},
+			{nil, nil, int64(2), nil, nil, nil, nil, nil, nil, nil},
+		},
+	})
+	defer q.connection.Disconnect()
+	res, err := q.Select(Settings{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	res[0]["Labels"].(map[string]string)["crop"] = "rye"
+	*res[0]["Name"].(*string) = "changed"
+	if res[1]["Labels"].(map[string]string)["crop"] != "wheat" || *res[1]["Name"].(*string) != "unknown" {
+		t.Errorf("expect every row to get its own copy of the default but was %v", res[1])
+	}
+}
+
+func TestSelectInvalidDefault(t *testing.T) {
+	q := New(nil)
+	_, err := q.generateSelect(InvalidDefaults{}, "", -1, -1)
+	if err == nil || !strings.Contains(err.Error(), "field Timeout tag default: invalid default soon") {
+		t.Errorf("expect the invalid default to be reported when the model is built but was %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// ExplainOptions controls how Explain runs the query
+type ExplainOptions struct {
+	// Analyze executes the query to get the actual timings, the execution is rolled back afterwards
+	Analyze bool
+	// Buffers adds the buffer usage to the plan, only valid together with Analyze
+	Buffers bool
+}
+
+// Plan is the parsed result of EXPLAIN (FORMAT JSON)
+type Plan struct {
+	SQL           string    `json:"-"`
+	Root          *PlanNode `json:"Plan"`
+	PlanningTime  float64   `json:"Planning Time"`
+	ExecutionTime float64   `json:"Execution Time"`
+}
+
+// PlanNode is one node of the plan tree
+type PlanNode struct {
+	NodeType            string      `json:"Node Type"`
+	ParentRelationship  string      `json:"Parent Relationship"`
+	RelationName        string      `json:"Relation Name"`
+	Schema              string      `json:"Schema"`
+	Alias               string      `json:"Alias"`
+	IndexName           string      `json:"Index Name"`
+	JoinType            string      `json:"Join Type"`
+	Filter              string      `json:"Filter"`
+	IndexCond           string      `json:"Index Cond"`
+	StartupCost         float64     `json:"Startup Cost"`
+	TotalCost           float64     `json:"Total Cost"`
+	PlanRows            float64     `json:"Plan Rows"`
+	PlanWidth           int64       `json:"Plan Width"`
+	ActualStartupTime   float64     `json:"Actual Startup Time"`
+	ActualTotalTime     float64     `json:"Actual Total Time"`
+	ActualRows          float64     `json:"Actual Rows"`
+	ActualLoops         float64     `json:"Actual Loops"`
+	RowsRemovedByFilter float64     `json:"Rows Removed by Filter"`
+	SharedHitBlocks     int64       `json:"Shared Hit Blocks"`
+	SharedReadBlocks    int64       `json:"Shared Read Blocks"`
+	SharedDirtiedBlocks int64       `json:"Shared Dirtied Blocks"`
+	SharedWrittenBlocks int64       `json:"Shared Written Blocks"`
+	TempReadBlocks      int64       `json:"Temp Read Blocks"`
+	TempWrittenBlocks   int64       `json:"Temp Written Blocks"`
+	Plans               []*PlanNode `json:"Plans"`
+}
+
+// Explain returns the plan of the query that Select generates for the target, where and params
+//
+// with opts.Analyze the query is executed inside a transaction that is rolled back,
+// inside of WithTx a savepoint is used instead
+func (ctx *Api) Explain(target interface{}, where string, opts ExplainOptions, params map[string]interface{}) (*Plan, error) {
+	if opts.Buffers && !opts.Analyze {
+		return nil, errors.New("explain option Buffers is only valid together with Analyze")
+	}
+	query, err := ctx.generateSelect(target, where, -1, -1)
+	if err != nil {
+		return nil, err
+	}
+	query, values := bindParameter(query, params)
+
+	buf := bytes.NewBuffer([]byte{})
+	buf.WriteString("explain (format json")
+	if opts.Analyze {
+		buf.WriteString(", analyze")
+	}
+	if opts.Buffers {
+		buf.WriteString(", buffers")
+	}
+	buf.WriteString(") ")
+	buf.WriteString(query)
+
+	c := context.Background()
+	var raw []byte
+	switch {
+	case !opts.Analyze && ctx.tx != nil:
+		err = ctx.tx.QueryRowContext(c, buf.String(), values...).Scan(&raw)
+	case !opts.Analyze:
+		err = ctx.db().QueryRowContext(c, buf.String(), values...).Scan(&raw)
+	case ctx.tx != nil:
+		raw, err = explainInSavepoint(c, ctx.tx, buf.String(), values)
+	default:
+		raw, err = explainInTransaction(c, ctx.db(), buf.String(), values)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := parsePlan(raw)
+	if err != nil {
+		return nil, err
+	}
+	plan.SQL = query
+	return plan, nil
+}
+
+func explainInTransaction(c context.Context, db *sql.DB, query string, values []interface{}) ([]byte, error) {
+	tx, err := db.BeginTx(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	var raw []byte
+	err = tx.QueryRowContext(c, query, values...).Scan(&raw)
+	return raw, err
+}
+
+func explainInSavepoint(c context.Context, tx *sql.Tx, query string, values []interface{}) ([]byte, error) {
+	if _, err := tx.ExecContext(c, "savepoint qsm_explain"); err != nil {
+		return nil, err
+	}
+	var raw []byte
+	err := tx.QueryRowContext(c, query, values...).Scan(&raw)
+	if _, rollbackErr := tx.ExecContext(c, "rollback to savepoint qsm_explain"); err == nil {
+		err = rollbackErr
+	}
+	return raw, err
+}
+
+func parsePlan(raw []byte) (*Plan, error) {
+	var plans []*Plan
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		return nil, err
+	}
+	if len(plans) != 1 || plans[0].Root == nil {
+		return nil, errors.New("explain returned no plan")
+	}
+	return plans[0], nil
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+const samplePlan = `[
+  {
+    "Plan": {
+      "Node Type": "Limit",
+      "Startup Cost": 0.00,
+      "Total Cost": 0.35,
+      "Plan Rows": 10,
+      "Plan Width": 64,
+      "Actual Startup Time": 0.010,
+      "Actual Total Time": 0.021,
+      "Actual Rows": 10,
+      "Actual Loops": 1,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Outer",
+          "Relation Name": "test_types",
+          "Alias": "tt",
+          "Startup Cost": 0.00,
+          "Total Cost": 22.70,
+          "Plan Rows": 1270,
+          "Plan Width": 64,
+          "Actual Rows": 10,
+          "Actual Loops": 1,
+          "Shared Hit Blocks": 1
+        }
+      ]
+    },
+    "Planning Time": 0.061,
+    "Execution Time": 0.042
+  }
+]`
+
+func TestParsePlan(t *testing.T) {
+	plan, err := parsePlan([]byte(samplePlan))
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if plan.Root.NodeType != "Limit" || plan.Root.TotalCost != 0.35 || plan.PlanningTime != 0.061 {
+		t.Errorf("invalid root node %+v", plan.Root)
+		return
+	}
+	if len(plan.Root.Plans) != 1 || plan.Root.Plans[0].RelationName != "test_types" ||
+		plan.Root.Plans[0].PlanRows != 1270 || plan.Root.Plans[0].SharedHitBlocks != 1 {
+		t.Errorf("invalid child node %+v", plan.Root.Plans)
+	}
+}
+
+func TestApi_ExplainAnalyze(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"QUERY PLAN"},
+		types:   []string{"json"},
+		rows:    [][]driver.Value{{[]byte(samplePlan)}},
+	})
+	defer q.connection.Disconnect()
+	q.RegisterColumnConvert("addOneConverter", "$column + 1")
+	plan, err := q.Explain(TestTypes{}, "where tt.id = :id", ExplainOptions{Analyze: true, Buffers: true}, map[string]interface{}{"id": 1})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if !strings.HasSuffix(plan.SQL, "where tt.id = $1") {
+		t.Errorf("expect the generated select but was %v", plan.SQL)
+	}
+	queries := fakeQueries()
+	if len(queries) != 3 || queries[0] != "begin" ||
+		queries[1] != "explain (format json, analyze, buffers) "+plan.SQL ||
+		queries[2] != "rollback" {
+		t.Errorf("expect explain analyze in a rolled back transaction but queries were %v", queries)
+	}
+}
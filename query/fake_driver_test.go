@@ -0,0 +1,152 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/nodejayes/qsm/connection"
+	"io"
+	"strings"
+	"sync"
+)
+
+// fakeResult is one canned result set returned by the fake driver
+type fakeResult struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+	err     error
+}
+
+type fakeDatabase struct {
+	sync.Mutex
+	results []fakeResult
+	queries []string
+}
+
+var fakeDb = &fakeDatabase{}
+
+func init() {
+	sql.Register("qsmfake", fakeDriver{})
+}
+
+// newFakeApi returns an Api connected to the fake driver that answers the queries with results in order
+func newFakeApi(results ...fakeResult) *Api {
+	fakeDb.Lock()
+	fakeDb.results = results
+	fakeDb.queries = nil
+	fakeDb.Unlock()
+	c := connection.New("fake", "qsmfake")
+	c.Connect(1)
+	return New(c)
+}
+
+func fakeQueries() []string {
+	fakeDb.Lock()
+	defer fakeDb.Unlock()
+	return append([]string{}, fakeDb.queries...)
+}
+
+func (ctx *fakeDatabase) log(query string) {
+	ctx.Lock()
+	defer ctx.Unlock()
+	ctx.queries = append(ctx.queries, query)
+}
+
+func (ctx *fakeDatabase) next(query string) fakeResult {
+	ctx.Lock()
+	defer ctx.Unlock()
+	ctx.queries = append(ctx.queries, query)
+	if len(ctx.results) < 1 {
+		return fakeResult{}
+	}
+	res := ctx.results[0]
+	ctx.results = ctx.results[1:]
+	return res
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (ctx *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (ctx *fakeConn) Close() error {
+	return nil
+}
+
+func (ctx *fakeConn) Begin() (driver.Tx, error) {
+	fakeDb.log("begin")
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (ctx *fakeTx) Commit() error {
+	fakeDb.log("commit")
+	return nil
+}
+
+func (ctx *fakeTx) Rollback() error {
+	fakeDb.log("rollback")
+	return nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (ctx *fakeStmt) Close() error {
+	return nil
+}
+
+func (ctx *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (ctx *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fakeDb.log(ctx.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (ctx *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := fakeDb.next(ctx.query)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{result: res}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	pos    int
+}
+
+func (ctx *fakeRows) Columns() []string {
+	return ctx.result.columns
+}
+
+func (ctx *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(ctx.result.types) {
+		return strings.ToUpper(ctx.result.types[index])
+	}
+	return ""
+}
+
+func (ctx *fakeRows) Close() error {
+	return nil
+}
+
+func (ctx *fakeRows) Next(dest []driver.Value) error {
+	if ctx.pos >= len(ctx.result.rows) {
+		return io.EOF
+	}
+	copy(dest, ctx.result.rows[ctx.pos])
+	ctx.pos++
+	return nil
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// isJSONType checks if the database type name is json or jsonb
+func isJSONType(dbType string) bool {
+	switch strings.ToUpper(dbType) {
+	case "JSON", "JSONB":
+		return true
+	}
+	return false
+}
+
+// jsonTarget checks if values of the type can be stored as json
+func jsonTarget(t reflect.Type) bool {
+	if t == rawMessageType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return t != timeType
+	case reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// readsJSON checks if the value of the column is unmarshalled into the field,
+// structs and maps are always read from json, slices and interfaces only from json columns
+func readsJSON(info *ModelInfo, dbType string, value interface{}) bool {
+	switch value.(type) {
+	case []uint8, string:
+	default:
+		return false
+	}
+	t := info.field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !jsonTarget(t) {
+		return false
+	}
+	if isJSONType(dbType) || isJSONType(info.DbType) {
+		return true
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+// writesJSON checks if the field is marshalled to json on write
+func writesJSON(info *ModelInfo) bool {
+	t := info.field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !jsonTarget(t) {
+		return false
+	}
+	if t == rawMessageType || isJSONType(info.DbType) {
+		return true
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+// decodeJSON unmarshals the json text into a new value of type t
+func decodeJSON(value interface{}, t reflect.Type) (interface{}, error) {
+	text, err := textValue(value)
+	if err != nil {
+		return nil, err
+	}
+	if t == rawMessageType {
+		return json.RawMessage(text), nil
+	}
+	res := reflect.New(t)
+	if err := json.Unmarshal([]byte(text), res.Interface()); err != nil {
+		return nil, err
+	}
+	return res.Elem().Interface(), nil
+}
+
+// encodeJSON marshals the field value into json text, nil values are written as NULL
+func encodeJSON(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if raw, ok := v.Interface().(json.RawMessage); ok {
+		return string(raw), nil
+	}
+	res, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(res), nil
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/mitchellh/mapstructure"
+	"testing"
+)
+
+type Document struct {
+	ID      int                    `column:"id"`
+	Dyn     DynStruct              `column:"dyn"`
+	Meta    map[string]interface{} `column:"meta"`
+	Tags    []string               `column:"tags" dbtype:"jsonb"`
+	Raw     json.RawMessage        `column:"raw"`
+	Details *DynStruct             `column:"details"`
+}
+
+func (ctx Document) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.documents"}, []string{"d"}
+}
+
+func TestSelectJSON(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"details", "dyn", "id", "meta", "raw", "tags"},
+		types:   []string{"jsonb", "json", "int4", "jsonb", "jsonb", "jsonb"},
+		rows: [][]driver.Value{
+			{nil, []byte(`{"hello":"world"}`), int64(1), []byte(`{"a":1}`), []byte(`[1, 2]`), []byte(`["x","y"]`)},
+		},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Document{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Document
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	d := res[0]
+	if d.Dyn.Hello != "world" || d.Meta["a"] != float64(1) || len(d.Tags) != 2 || d.Tags[1] != "y" ||
+		string(d.Raw) != "[1, 2]" || d.Details != nil {
+		t.Errorf("unexpected result %v", d)
+	}
+
+	values, err := q.Values(d)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["dyn"] != `{"hello":"world"}` || values["meta"] != `{"a":1}` || values["tags"] != `["x","y"]` ||
+		values["raw"] != "[1, 2]" || values["details"] != nil {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestSelectJSONError(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"dyn", "id"},
+		types:   []string{"json", "int4"},
+		rows:    [][]driver.Value{{[]byte(`{"hello":1}`), int64(1)}},
+	})
+	defer q.connection.Disconnect()
+	_, err := q.Select(Document{}, "", -1, -1)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Field != "Dyn" || convErr.DbType != "JSON" {
+		t.Errorf("expect a ConversionError of the field Dyn but was %v", err)
+	}
+}
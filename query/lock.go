@@ -0,0 +1,133 @@
+package query
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// LockStrength the row locking clause that is added to a select
+type LockStrength = string
+
+const (
+	ForUpdate      LockStrength = "for update"
+	ForNoKeyUpdate LockStrength = "for no key update"
+	ForShare       LockStrength = "for share"
+	ForKeyShare    LockStrength = "for key share"
+)
+
+// LockWait defines what happens when a selected row is already locked
+type LockWait = string
+
+const (
+	Wait       LockWait = ""
+	NoWait     LockWait = "nowait"
+	SkipLocked LockWait = "skip locked"
+)
+
+// Lock describes the row locking of a select
+type Lock struct {
+	Strength LockStrength
+	// Of limits the lock to the given source aliases of the model
+	Of   []string
+	Wait LockWait
+}
+
+// ErrNoTransaction is returned when a locking select is executed outside of a transaction
+var ErrNoTransaction = errors.New("row locking is only valid inside a transaction, use WithTx")
+
+// LockNotAvailableError is returned when a locking select with NoWait hits a row that is already locked
+type LockNotAvailableError struct {
+	Err error
+}
+
+func (ctx *LockNotAvailableError) Error() string {
+	return fmt.Sprintf("lock not available: %v", ctx.Err.Error())
+}
+
+func (ctx *LockNotAvailableError) Unwrap() error {
+	return ctx.Err
+}
+
+// WithTx returns a copy of the Api that runs all queries inside of tx
+//
+// the copy shares the registered converters with the original Api
+func (ctx *Api) WithTx(tx *sql.Tx) *Api {
+	res := *ctx
+	res.tx = tx
+	return &res
+}
+
+// SelectLocked works like Select and locks the selected rows until the transaction ends
+func (ctx *Api) SelectLocked(target interface{}, where string, limit, offset int, lock Lock, args ...map[string]interface{}) ([]map[string]interface{}, error) {
+	if ctx.tx == nil {
+		return nil, ErrNoTransaction
+	}
+	_, _, aliases, err := ctx.sources(target)
+	if err != nil {
+		return nil, err
+	}
+	clause, err := lock.clause(aliases)
+	if err != nil {
+		return nil, err
+	}
+	query, err := ctx.generateSelect(target, where, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	query, values := bindArgs(query+clause, args)
+	return ctx.selectQuery(target, query, values)
+}
+
+func (ctx Lock) clause(aliases []string) (string, error) {
+	switch ctx.Strength {
+	case ForUpdate, ForNoKeyUpdate, ForShare, ForKeyShare:
+		break
+	default:
+		return "", errors.New(fmt.Sprintf("lock strength %v is not supported", ctx.Strength))
+	}
+	switch ctx.Wait {
+	case Wait, NoWait, SkipLocked:
+		break
+	default:
+		return "", errors.New(fmt.Sprintf("lock wait %v is not supported", ctx.Wait))
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	buf.WriteString(" ")
+	buf.WriteString(ctx.Strength)
+	if len(ctx.Of) > 0 {
+		for _, of := range ctx.Of {
+			if !containsString(sourceAliasNames(aliases), of) {
+				return "", errors.New(fmt.Sprintf("lock of %v is not a source alias of the model", of))
+			}
+		}
+		buf.WriteString(" of ")
+		buf.WriteString(strings.Join(ctx.Of, ", "))
+	}
+	if len(ctx.Wait) > 0 {
+		buf.WriteString(" ")
+		buf.WriteString(ctx.Wait)
+	}
+	return buf.String(), nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func wrapLockError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "55P03" {
+		return &LockNotAvailableError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"errors"
+	"github.com/lib/pq"
+	"strings"
+	"testing"
+)
+
+func TestLock_Clause(t *testing.T) {
+	q := New(nil)
+	q.RegisterColumnConvert("addOneConverter", "$column + 1")
+	clause, err := Lock{Strength: ForUpdate, Of: []string{"tt"}, Wait: SkipLocked}.clause([]string{"tt"})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	query, err := q.generateSelect(TestTypes{}, "where tt.id > 5", 10, 20)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	query += clause
+	if !strings.HasSuffix(query, "where tt.id > 5 limit 10 offset 20 for update of tt skip locked") {
+		t.Errorf("expect locking clause after limit and offset but was %v", query)
+	}
+	_, err = Lock{Strength: ForShare, Of: []string{"xx"}}.clause([]string{"tt"})
+	if err == nil {
+		t.Errorf("expect an error for an unknown source alias")
+	}
+}
+
+func TestApi_SelectLockedOutsideTransaction(t *testing.T) {
+	q := New(nil)
+	_, err := q.SelectLocked(TestTypes{}, "", 1, -1, Lock{Strength: ForUpdate, Wait: NoWait})
+	if err != ErrNoTransaction {
+		t.Errorf("expect ErrNoTransaction but was %v", err)
+	}
+}
+
+func TestWrapLockError(t *testing.T) {
+	err := wrapLockError(&pq.Error{Code: "55P03", Message: "could not obtain lock on row"})
+	var lockErr *LockNotAvailableError
+	if !errors.As(err, &lockErr) {
+		t.Errorf("expect a LockNotAvailableError but was %v", err)
+	}
+	err = wrapLockError(&pq.Error{Code: "42P01"})
+	if errors.As(err, &lockErr) {
+		t.Errorf("expect other errors to be returned unchanged")
+	}
+}
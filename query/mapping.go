@@ -0,0 +1,204 @@
+package query
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ModelMapping declares the sources and columns of a type in code instead of struct tags and GetSources
+//
+// use it for types that can't be tagged, like generated or third party structs
+type ModelMapping struct {
+	registry *modelRegistry
+	typ      reflect.Type
+	types    []string
+	sources  []string
+	aliases  []string
+	fields   map[string]map[string]string
+}
+
+// FieldMapping declares the tags of one field of a ModelMapping
+type FieldMapping struct {
+	mapping *ModelMapping
+	name    string
+}
+
+// Map returns the mapping of the type of target, the mapping is created on first use
+//
+// a mapped field is read exactly like a field with the same struct tags, the struct tags of a mapped field are ignored
+func (ctx *Api) Map(target interface{}) *ModelMapping {
+	t := modelType(target)
+	ctx.models.Lock()
+	defer ctx.models.Unlock()
+	if m, ok := ctx.models.mappings[t]; ok {
+		return m
+	}
+	m := &ModelMapping{
+		registry: ctx.models,
+		typ:      t,
+		fields:   make(map[string]map[string]string),
+	}
+	ctx.models.mappings[t] = m
+	ctx.models.invalidate(t)
+	return m
+}
+
+// Source adds a source like the entries of GetSources, typ is the keyword like from or left join
+func (ctx *ModelMapping) Source(typ, source, alias string) *ModelMapping {
+	ctx.registry.Lock()
+	defer ctx.registry.Unlock()
+	ctx.types = append(ctx.types, typ)
+	ctx.sources = append(ctx.sources, source)
+	ctx.aliases = append(ctx.aliases, alias)
+	ctx.registry.invalidate(ctx.typ)
+	return ctx
+}
+
+// From adds the main source of the select
+func (ctx *ModelMapping) From(source, alias string) *ModelMapping {
+	return ctx.Source("from", source, alias)
+}
+
+// Field maps the field with the name to the column, the column has the same format as the column tag
+//
+// fields of nested structs are named by their path like Address.Street
+func (ctx *ModelMapping) Field(name, column string) *FieldMapping {
+	return ctx.tag(name, "column", column)
+}
+
+// Nested maps the fields of the nested struct field with the name using the column prefix
+func (ctx *ModelMapping) Nested(name, prefix string) *FieldMapping {
+	return ctx.tag(name, "prefix", prefix)
+}
+
+// Ignore excludes the field with the name from the mapping
+func (ctx *ModelMapping) Ignore(name string) *ModelMapping {
+	return ctx.tag(name, "column", "-").mapping
+}
+
+// GetSources returns the declared sources in the format of IModel
+func (ctx *ModelMapping) GetSources() ([]string, []string, []string) {
+	ctx.registry.RLock()
+	defer ctx.registry.RUnlock()
+	return append([]string{}, ctx.types...), append([]string{}, ctx.sources...), append([]string{}, ctx.aliases...)
+}
+
+func (ctx *ModelMapping) tag(name, key, value string) *FieldMapping {
+	ctx.registry.Lock()
+	defer ctx.registry.Unlock()
+	tags, ok := ctx.fields[name]
+	if !ok {
+		tags = make(map[string]string)
+		ctx.fields[name] = tags
+	}
+	tags[key] = value
+	ctx.registry.invalidate(ctx.typ)
+	return &FieldMapping{
+		mapping: ctx,
+		name:    name,
+	}
+}
+
+// structTags returns the declared tags of the fields in struct tag format
+func (ctx *ModelMapping) structTags() map[string]reflect.StructTag {
+	res := make(map[string]reflect.StructTag)
+	for name, tags := range ctx.fields {
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf := bytes.NewBuffer([]byte{})
+		for idx, k := range keys {
+			if idx > 0 {
+				buf.WriteString(" ")
+			}
+			buf.WriteString(k)
+			buf.WriteString(":")
+			buf.WriteString(strconv.Quote(tags[k]))
+		}
+		res[name] = reflect.StructTag(buf.String())
+	}
+	return res
+}
+
+// Src binds the field to a source alias like the src tag
+func (ctx *FieldMapping) Src(alias string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "src", alias)
+}
+
+// Alias sets the result alias like the alias tag
+func (ctx *FieldMapping) Alias(alias string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "alias", alias)
+}
+
+// Read sets the read converter like the read tag
+func (ctx *FieldMapping) Read(converter string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "read", converter)
+}
+
+// Write sets the write converter like the write tag
+func (ctx *FieldMapping) Write(converter string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "write", converter)
+}
+
+// DbRead sets the read expression like the dbread tag
+func (ctx *FieldMapping) DbRead(template string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "dbread", template)
+}
+
+// DbWrite sets the write expression like the dbwrite tag
+func (ctx *FieldMapping) DbWrite(template string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "dbwrite", template)
+}
+
+// Default sets the value for NULL like the default tag
+func (ctx *FieldMapping) Default(value string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "default", value)
+}
+
+// DbType sets the database type of the column like the dbtype tag
+func (ctx *FieldMapping) DbType(dbType string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, "dbtype", dbType)
+}
+
+// Tag sets any other tag of the field
+func (ctx *FieldMapping) Tag(key, value string) *FieldMapping {
+	return ctx.mapping.tag(ctx.name, key, value)
+}
+
+// Field continues with the next field of the mapping
+func (ctx *FieldMapping) Field(name, column string) *FieldMapping {
+	return ctx.mapping.Field(name, column)
+}
+
+// Mapping returns the mapping the field belongs to
+func (ctx *FieldMapping) Mapping() *ModelMapping {
+	return ctx.mapping
+}
+
+// sources returns the sources of the target from its mapping or from GetSources
+func (ctx *Api) sources(target interface{}) ([]string, []string, []string, error) {
+	ctx.models.RLock()
+	m, ok := ctx.models.mappings[modelType(target)]
+	ctx.models.RUnlock()
+	if ok {
+		// GetSources locks the registry, the mapping may get sources concurrently
+		if types, sources, aliases := m.GetSources(); len(sources) > 0 {
+			return types, sources, aliases, nil
+		}
+	}
+	if model, ok := target.(IModel); ok {
+		types, sources, aliases := model.GetSources()
+		if len(types) != len(sources) || len(sources) != len(aliases) {
+			return nil, nil, nil, errors.New(fmt.Sprintf("GetSources of %v returns %v types, %v sources and %v aliases",
+				modelType(target), len(types), len(sources), len(aliases)))
+		}
+		return types, sources, aliases, nil
+	}
+	return nil, nil, nil, errors.New(fmt.Sprintf("%v has no sources, implement IModel or declare them with Map", modelType(target)))
+}
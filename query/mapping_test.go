@@ -0,0 +1,159 @@
+package query
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ThirdParty has the same fields as TestTypes without tags and GetSources
+type ThirdParty struct {
+	ID       int
+	Age      int
+	Height   float64
+	Name     string
+	Birthday time.Time
+	Dyn      DynStruct
+	Dynb     DynStruct
+	Active   bool
+	Cache    map[string]string
+}
+
+func TestApi_Map(t *testing.T) {
+	q := New(nil)
+	q.RegisterColumnConvert("addOneConverter", "$column + 1")
+	q.Map(ThirdParty{}).
+		From("public.test_types", "tt").
+		Field("ID", "tt.id").
+		Field("Age", "tt.age->addOneConverter").
+		Field("Height", "tt.height").
+		Field("Name", "tt.name").
+		Field("Birthday", "tt.birthday").
+		Field("Dyn", "tt.dyn").
+		Field("Dynb", "tt.dynb").
+		Field("Active", "tt.active").Alias("ac").
+		Mapping().Ignore("Cache")
+
+	expected, err := q.generateSelect(TestTypes{}, "where tt.id = :id", 1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	query, err := q.generateSelect(&ThirdParty{}, "where tt.id = :id", 1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+		return
+	}
+
+	tagged, _ := q.GetModelInfo(TestTypes{}, ColumnName)
+	mapped, err := q.GetModelInfo(ThirdParty{}, ColumnName)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(tagged) != len(mapped) {
+		t.Errorf("expect %v mapped fields but was %v", len(tagged), len(mapped))
+		return
+	}
+	for alias, info := range tagged {
+		other := mapped[alias]
+		if other == nil || other.FieldName != info.FieldName || other.Name != info.Name ||
+			other.Source != info.Source || other.ColumnConverter != info.ColumnConverter ||
+			!reflect.DeepEqual(other.Index, info.Index) {
+			t.Errorf("expect mapping of %v to be equal to the tags", alias)
+		}
+	}
+}
+
+func TestApi_MapWithoutSources(t *testing.T) {
+	q := New(nil)
+	q.Map(ThirdParty{}).Field("ID", "id")
+	if _, err := q.generateSelect(ThirdParty{}, "", -1, -1); err == nil {
+		t.Errorf("expect an error for a type without sources")
+	}
+}
+
+func TestApi_MapConcurrentSources(t *testing.T) {
+	q := New(nil)
+	m := q.Map(ThirdParty{})
+	m.Field("ID", "tt.id")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m.From("public.test_types", "tt")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_, _, _, _ = q.sources(ThirdParty{})
+	}
+	<-done
+	if _, sources, _, err := q.sources(ThirdParty{}); err != nil || len(sources) < 1 {
+		t.Errorf("expect the mapped sources but was %v %v", sources, err)
+	}
+}
+
+func TestApi_MapUnknownField(t *testing.T) {
+	q := New(nil)
+	q.Map(ThirdParty{}).
+		From("public.test_types", "tt").
+		Field("ID", "tt.id").
+		Field("Nmae", "tt.name").
+		Mapping().Ignore("Dyn.Missing")
+	err := q.RegisterModel(ThirdParty{})
+	for _, expected := range []string{
+		"field Nmae: mapped field does not exist in query.ThirdParty",
+		"field Dyn.Missing: mapped field does not exist in query.ThirdParty",
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expect %v in %v", expected, err)
+		}
+	}
+	if _, err = q.generateSelect(ThirdParty{}, "", -1, -1); err == nil {
+		t.Errorf("expect the select to fail for an unknown mapped field")
+	}
+}
+
+func TestApi_MapConcurrentModel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	q := New(nil)
+	m := q.Map(ThirdParty{}).From("public.test_types", "tt")
+	m.Field("ID", "tt.id")
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					_, _ = q.model(ThirdParty{})
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		m.Field("Name", "tt.name")
+		m.Field("Name", "tt.title")
+	}
+	close(done)
+	wg.Wait()
+	infos, err := q.GetModelInfo(ThirdParty{}, FieldName)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if infos["Name"] == nil || infos["Name"].Name != "title" {
+		t.Errorf("expect the cached model to use the last mapping but was %+v", infos["Name"])
+	}
+}
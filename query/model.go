@@ -1,7 +1,11 @@
 package query
 
 import (
+	"fmt"
+	"github.com/nodejayes/qsm/converter"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -12,32 +16,206 @@ const (
 	ColumnName ModelInfoMapMaster = "column_name_master"
 )
 
+// UntaggedFieldPolicy defines how exported fields without a column tag are handled
+type UntaggedFieldPolicy = string
+
+const (
+	// SkipUntagged ignores fields without a column tag so models can have helper fields
+	SkipUntagged UntaggedFieldPolicy = "skip"
+	// ErrorUntagged reports fields without a column tag as invalid model
+	ErrorUntagged UntaggedFieldPolicy = "error"
+	// DeriveUntagged derives the column name from the field name
+	DeriveUntagged UntaggedFieldPolicy = "derive"
+)
+
+// modelOptions controls how the model metadata is read from the struct
+type modelOptions struct {
+	untagged UntaggedFieldPolicy
+	naming   NamingStrategy
+	// tags replaces the struct tags of the fields by field path, used for the programmatic mappings
+	tags map[string]reflect.StructTag
+	// mapped collects the paths of tags that matched a field
+	mapped map[string]bool
+}
+
+var defaultModelOptions = modelOptions{
+	untagged: SkipUntagged,
+	naming:   SnakeCase,
+}
+
 type IModel interface {
 	GetSources() ([]string, []string, []string)
 }
 
 type ModelInfo struct {
-	FieldName              string
-	ColumnName             string
+	// FieldName the name of the field, fields of nested structs are separated by a dot like Address.Street
+	FieldName  string
+	ColumnName string
+	// Column the column tag as it was written, ColumnName differs from it when a dbread template is used
+	Column string
+	// Source the alias of the source from GetSources the column belongs to, taken from the src tag
+	Source string
+	// Name the plain column name without source alias and column converter
+	Name string
+	// ColumnConverter the name of the column converter after -> in the column tag
+	ColumnConverter        string
+	ReadDatabaseConverter  string
 	WriteDatabaseConverter string
 	ReadConverter          string
 	WriteConverter         string
 	Alias                  string
+	// Null the NullPolicy of the null tag, empty means NullAsZero
+	Null NullPolicy
+	// DbType the database type of the column from the dbtype tag like jsonb, it selects the encoding on write
+	DbType string
+	// ResultAlias the unique name of the result column the field is read from
+	ResultAlias string
+	// Index the index sequence of the field for reflect FieldByIndex
+	Index []int
+	// derived is set when the column name was derived by the naming strategy and has to be used case sensitive
+	derived bool
+	// field the struct field with the tags the info was read from
+	field reflect.StructField
+	// defaultValue the value of the default tag parsed into the field type, used for NULL when hasDefault is set
+	defaultValue interface{}
+	hasDefault   bool
+	// expression is set when the column tag is a sql expression like count(*), it is used as written
+	expression bool
+	// extra marks the catch-all field tagged with extra that collects the unmapped result columns
+	extra bool
 }
 
 func GetModelInfo(target interface{}, master ModelInfoMapMaster) map[string]*ModelInfo {
 	res := make(map[string]*ModelInfo)
+	infos, _ := readModelInfo(target, defaultModelOptions)
+	for _, info := range infos {
+		if info.extra {
+			continue
+		}
+		switch master {
+		case FieldName:
+			res[info.FieldName] = info
+			break
+		case ColumnName:
+			res[info.ResultAlias] = info
+			break
+		default:
+			panic("ModelInfoMapMaster not supported only use FieldName or ColumnName!")
+		}
+	}
+	return res
+}
+
+func readModelInfo(target interface{}, opts modelOptions) ([]*ModelInfo, []ModelProblem) {
 	t := reflect.TypeOf(target)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	if t.Kind() != reflect.Struct {
+		return nil, []ModelProblem{{Message: "model must be a struct"}}
+	}
+	var problems []ModelProblem
+	opts.mapped = make(map[string]bool)
+	res := readStructInfo(t, nil, "", "", "", opts, &problems)
+	names := make([]string, 0, len(opts.tags))
+	for name := range opts.tags {
+		if !opts.mapped[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, ModelProblem{Field: name, Message: fmt.Sprintf("mapped field does not exist in %v", t)})
+	}
+	problems = append(problems, assignResultAliases(res)...)
+	return res, problems
+}
+
+// readStructInfo reads the fields of t, embedded structs are flattened and nested structs
+// with a prefix or src tag are read with the column prefix and source of the parent field
+//
+// unexported fields and fields tagged with column:"-" are skipped
+func readStructInfo(t reflect.Type, index []int, path, prefix, source string, opts modelOptions, problems *[]ModelProblem) []*ModelInfo {
+	var res []*ModelInfo
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
+		fieldPath := path + field.Name
+		if tag, ok := opts.tags[fieldPath]; ok {
+			field.Tag = tag
+			opts.mapped[fieldPath] = true
+		}
+		if len(field.PkgPath) > 0 || field.Tag.Get("column") == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if _, ok := field.Tag.Lookup("extra"); ok {
+			if field.Type != reflect.TypeOf(map[string]interface{}{}) {
+				*problems = append(*problems, ModelProblem{Field: fieldPath, Tag: "extra", Message: "extra field must be of type map[string]interface{}"})
+				continue
+			}
+			res = append(res, &ModelInfo{
+				FieldName: fieldPath,
+				Index:     fieldIndex,
+				extra:     true,
+				field:     field,
+			})
+			continue
+		}
+		if nested, ok := nestedStruct(field); ok {
+			nestedSource := source
+			if c := field.Tag.Get("src"); len(c) > 0 {
+				nestedSource = c
+			}
+			res = append(res, readStructInfo(nested, fieldIndex, fieldPath+".", prefix+field.Tag.Get("prefix"), nestedSource, opts, problems)...)
+			continue
+		}
+		column, ok := field.Tag.Lookup("column")
+		if !ok || len(column) < 1 {
+			// an empty column tag is handled like a missing one
+			ok = false
+			switch opts.untagged {
+			case DeriveUntagged:
+				column = opts.naming(field.Name)
+				break
+			case ErrorUntagged:
+				message := "field has no column tag"
+				if _, tagged := field.Tag.Lookup("column"); tagged {
+					message = "field has an empty column tag"
+				}
+				*problems = append(*problems, ModelProblem{Field: fieldPath, Tag: "column", Message: message})
+				continue
+			default:
+				continue
+			}
+		}
+
 		info := new(ModelInfo)
-		info.FieldName = field.Name
-		c := field.Tag.Get("column")
+		info.FieldName = fieldPath
+		info.Index = fieldIndex
+		info.Source = source
+		info.derived = !ok
+		info.field = field
+		if len(column) > 0 {
+			info.ColumnName = column
+			info.Column = column
+			var columnSource string
+			columnSource, info.Name, info.ColumnConverter = parseColumn(column)
+			info.expression = !isIdentifier(info.Name)
+			if !info.expression {
+				info.Name = prefix + info.Name
+			}
+			if len(columnSource) > 0 {
+				info.Source = columnSource
+			}
+		}
+		c := field.Tag.Get("src")
 		if len(c) > 0 {
-			info.ColumnName = c
+			info.Source = c
+		}
+		c = field.Tag.Get("dbread")
+		if len(c) > 0 {
+			info.ReadDatabaseConverter = c
+			info.ColumnName = info.readExpression(columnExpression(info.Column), info.Source)
 		}
 		c = field.Tag.Get("dbwrite")
 		if len(c) > 0 {
@@ -55,27 +233,204 @@ func GetModelInfo(target interface{}, master ModelInfoMapMaster) map[string]*Mod
 		if len(c) > 0 {
 			info.Alias = c
 		}
-		switch master {
-		case FieldName:
-			res[info.FieldName] = info
-			break
-		case ColumnName:
-			if len(info.Alias) > 0 {
-				res[info.Alias] = info
-			} else {
-				tmp := info.ColumnName
-				if strings.Contains(tmp, ".") {
-					tmp = strings.Split(tmp, ".")[1]
-				}
-				if strings.Contains(tmp, "->") {
-					tmp = strings.Split(tmp, "->")[0]
-				}
-				res[tmp] = info
-			}
-			break
-		default:
-			panic("ModelInfoMapMaster not supported only use FieldName or ColumnName!")
+		c = field.Tag.Get("dbtype")
+		if len(c) > 0 {
+			info.DbType = c
 		}
+		if v, ok, err := converter.ParseDefault(field); err != nil {
+			*problems = append(*problems, ModelProblem{Field: fieldPath, Tag: "default", Message: err.Error()})
+		} else if ok {
+			info.defaultValue = v
+			info.hasDefault = true
+		}
+		c = field.Tag.Get("null")
+		if !validNullPolicy(c) {
+			*problems = append(*problems, ModelProblem{Field: fieldPath, Tag: "null", Message: fmt.Sprintf("unknown null policy %v", c)})
+		}
+		info.Null = c
+		res = append(res, info)
 	}
 	return res
 }
+
+// nestedStruct checks if the fields of a struct field are mapped to columns instead of the field itself
+//
+// this is the case for embedded structs and for named structs with a prefix or src tag but without a column tag
+func nestedStruct(field reflect.StructField) (reflect.Type, bool) {
+	if _, ok := field.Tag.Lookup("column"); ok {
+		return nil, false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if ptr := reflect.PtrTo(t); ptr.Implements(scannerType) || ptr.Implements(fromDBType) {
+		// types like sql.NullString and Null[T] scan themselves from one column
+		return nil, false
+	}
+	if field.Anonymous {
+		return t, true
+	}
+	if !hasExportedFields(t) {
+		// structs like time.Time are values of one column, there is nothing to read from their fields
+		return nil, false
+	}
+	_, hasPrefix := field.Tag.Lookup("prefix")
+	_, hasSource := field.Tag.Lookup("src")
+	return t, hasPrefix || hasSource
+}
+
+// hasExportedFields checks if the struct type has at least one exported field
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if len(t.Field(i).PkgPath) < 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceAliasNames returns the alias names of GetSources, a join condition after the alias is ignored
+func sourceAliasNames(aliases []string) []string {
+	res := make([]string, len(aliases))
+	for idx, alias := range aliases {
+		fields := strings.Fields(alias)
+		if len(fields) > 0 {
+			res[idx] = fields[0]
+		}
+	}
+	return res
+}
+
+// parseColumn splits a column tag like tt.age->addOne into the source alias, the column name and the column converter
+func parseColumn(column string) (string, string, string) {
+	var source string
+	column, converter := splitColumnConverter(column)
+	if idx := strings.Index(column, "."); idx > -1 && isIdentifier(column[:idx]) && isIdentifier(column[idx+1:]) {
+		source = column[:idx]
+		column = column[idx+1:]
+	}
+	return source, column, converter
+}
+
+// isIdentifier checks if the name is a plain or quoted sql identifier and not an expression like count(*)
+func isIdentifier(name string) bool {
+	if len(name) > 1 && name[0] == '"' && name[len(name)-1] == '"' {
+		return !strings.Contains(name[1:len(name)-1], "\"")
+	}
+	for idx, ch := range name {
+		switch {
+		case ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+		case idx > 0 && (ch == '$' || (ch >= '0' && ch <= '9')):
+		default:
+			return false
+		}
+	}
+	return len(name) > 0
+}
+
+// maxAliasLength is the postgres identifier limit, longer result aliases are truncated by the database
+const maxAliasLength = 63
+
+// assignResultAliases uses the alias tag or the plain column name as result alias
+//
+// column names that occur in more than one source are prefixed with their source alias,
+// remaining collisions get a numeric suffix. the aliases only depend on the field order so they are stable.
+// an alias tag that is used twice can't be resolved and is returned as problem
+func assignResultAliases(infos []*ModelInfo) []ModelProblem {
+	taken := make(map[string]string)
+	counts := make(map[string]int)
+	var duplicates []ModelProblem
+	for _, info := range infos {
+		if info.extra {
+			continue
+		}
+		if len(info.Alias) < 1 {
+			counts[info.Name]++
+			continue
+		}
+		alias := truncateAlias(info.Alias)
+		if other, ok := taken[alias]; ok {
+			duplicates = append(duplicates, ModelProblem{
+				Field:   info.FieldName,
+				Tag:     "alias",
+				Message: fmt.Sprintf("alias %v is used by field %v and %v", alias, other, info.FieldName),
+			})
+			continue
+		}
+		taken[alias] = info.FieldName
+		info.ResultAlias = alias
+	}
+
+	for _, info := range infos {
+		if info.extra || len(info.Alias) > 0 {
+			continue
+		}
+		candidate := info.Name
+		if counts[info.Name] > 1 && len(info.Source) > 0 {
+			candidate = info.Source + "_" + info.Name
+		}
+		alias := truncateAlias(candidate)
+		for counter := 2; ; counter++ {
+			if _, ok := taken[alias]; !ok {
+				break
+			}
+			suffix := "_" + strconv.Itoa(counter)
+			alias = candidate
+			if len(alias)+len(suffix) > maxAliasLength {
+				alias = alias[:maxAliasLength-len(suffix)]
+			}
+			alias += suffix
+		}
+		taken[alias] = info.FieldName
+		info.ResultAlias = alias
+	}
+
+	return duplicates
+}
+
+func truncateAlias(alias string) string {
+	if len(alias) > maxAliasLength {
+		return alias[:maxAliasLength]
+	}
+	return alias
+}
+
+// qualifiedColumn returns the column name prefixed with the source alias, expressions are returned as written
+func (ctx *ModelInfo) qualifiedColumn(source string) string {
+	name := ctx.Name
+	if ctx.expression {
+		return name
+	}
+	if ctx.derived && strings.ToLower(name) != name {
+		name = fmt.Sprintf("\"%v\"", name)
+	}
+	if len(source) < 1 {
+		return name
+	}
+	return source + "." + name
+}
+
+// writeExpression applies the dbwrite template of the field to the placeholder of the bound value,
+// a quoted '$value' is replaced as well so the value is never inlined into the statement
+func (ctx *ModelInfo) writeExpression(placeholder string) string {
+	if len(ctx.WriteDatabaseConverter) < 1 {
+		return placeholder
+	}
+	res := strings.ReplaceAll(ctx.WriteDatabaseConverter, "'$value'", placeholder)
+	return strings.ReplaceAll(res, "$value", placeholder)
+}
+
+// readExpression applies the dbread template of the field to the column expression
+//
+// $column is replaced with the column expression and $alias with the source alias
+func (ctx *ModelInfo) readExpression(column, source string) string {
+	if len(ctx.ReadDatabaseConverter) < 1 {
+		return column
+	}
+	res := strings.ReplaceAll(ctx.ReadDatabaseConverter, "$column", column)
+	return strings.ReplaceAll(res, "$alias", source)
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,3 +48,138 @@ func TestGetModelInfo(t *testing.T) {
 		return
 	}
 }
+
+type FieldWithFarm struct {
+	ID       int    `src:"f" column:"id"`
+	Name     string `src:"f" column:"name"`
+	FarmID   int    `src:"fa" column:"id"`
+	FarmName string `src:"fa" column:"name" alias:"farm"`
+}
+
+func (ctx FieldWithFarm) GetSources() ([]string, []string, []string) {
+	return []string{
+			"from",
+			"join",
+		}, []string{
+			"public.fields",
+			"public.farms",
+		}, []string{
+			"f",
+			"fa on fa.id = f.farm_id",
+		}
+}
+
+func TestGetModelInfoSource(t *testing.T) {
+	info := GetModelInfo(&FieldWithFarm{}, ColumnName)
+	if info["f_id"].FieldName != "ID" || info["fa_id"].FieldName != "FarmID" {
+		t.Errorf("expect column names of different sources to get unique result aliases")
+		return
+	}
+	if info["name"].FieldName != "Name" || info["farm"].FieldName != "FarmName" {
+		t.Errorf("expect unique column names to keep their name as result alias")
+	}
+}
+
+type CollidingColumns struct {
+	ID      int    `column:"id"`
+	OtherID int    `column:"id"`
+	FID     int    `src:"f" column:"id"`
+	Taken   string `column:"name" alias:"f_id"`
+}
+
+type DuplicateAlias struct {
+	ID   int    `column:"id" alias:"key"`
+	Name string `column:"name" alias:"key"`
+}
+
+func (ctx DuplicateAlias) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.x"}, []string{"x"}
+}
+
+func TestAssignResultAliases(t *testing.T) {
+	infos, problems := readModelInfo(CollidingColumns{}, defaultModelOptions)
+	if len(problems) > 0 {
+		t.Errorf("expect no problems but was: %v", problems)
+		return
+	}
+	aliases := make([]string, len(infos))
+	for idx, info := range infos {
+		aliases[idx] = info.ResultAlias
+	}
+	if aliases[0] != "id" || aliases[1] != "id_2" || aliases[2] != "f_id_2" || aliases[3] != "f_id" {
+		t.Errorf("expect collision free aliases but was %v", aliases)
+	}
+}
+
+func TestDuplicateAlias(t *testing.T) {
+	q := New(nil)
+	_, err := q.generateSelect(DuplicateAlias{}, "", -1, -1)
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) || !strings.Contains(err.Error(), "alias key is used by field ID and Name") {
+		t.Errorf("expect a ModelError for the duplicate alias but was %v", err)
+	}
+}
+
+type HelperFields struct {
+	ID        int    `column:"id"`
+	Ignored   string `column:"-"`
+	Helper    string
+	FarmID    int
+	HTTPState string
+	internal  int
+}
+
+func TestUntaggedFieldPolicy(t *testing.T) {
+	info := GetModelInfo(HelperFields{}, FieldName)
+	if len(info) != 1 || info["ID"] == nil {
+		t.Errorf("expect untagged, ignored and unexported fields to be skipped but was %v", info)
+		return
+	}
+
+	q := New(nil)
+	q.SetUntaggedFieldPolicy(DeriveUntagged)
+	info, err := q.GetModelInfo(HelperFields{}, FieldName)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(info) != 4 || info["Helper"].Name != "helper" || info["FarmID"].Name != "farm_id" || info["HTTPState"].Name != "http_state" {
+		t.Errorf("expect column names to be derived from the field names")
+		return
+	}
+
+	q.SetUntaggedFieldPolicy(ErrorUntagged)
+	_, err = q.GetModelInfo(HelperFields{}, FieldName)
+	if err == nil || !strings.Contains(err.Error(), "field Helper tag column: field has no column tag") {
+		t.Errorf("expect an error for untagged fields but was %v", err)
+	}
+}
+
+type EmptyColumn struct {
+	ID   int    `column:"id"`
+	Name string `column:""`
+}
+
+func (ctx EmptyColumn) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.x"}, []string{"x"}
+}
+
+func TestEmptyColumnTag(t *testing.T) {
+	q := New(nil)
+	query, err := q.generateSelect(EmptyColumn{}, "", -1, -1)
+	if err != nil || query != "select x.id as \"id\" from public.x x " {
+		t.Errorf("expect the empty column tag to be skipped but was %v %v", query, err)
+	}
+
+	q.SetUntaggedFieldPolicy(DeriveUntagged)
+	query, _ = q.generateSelect(EmptyColumn{}, "", -1, -1)
+	if query != "select x.id as \"id\", x.name as \"name\" from public.x x " {
+		t.Errorf("expect the column name to be derived but was %v", query)
+	}
+
+	q.SetUntaggedFieldPolicy(ErrorUntagged)
+	err = q.RegisterModel(EmptyColumn{})
+	if err == nil || !strings.Contains(err.Error(), "field Name tag column: field has an empty column tag") {
+		t.Errorf("expect an error for the empty column tag but was %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package query
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the column name of a field without column tag from the field name
+type NamingStrategy = func(fieldName string) string
+
+// SnakeCase converts a field name like FarmID to farm_id
+func SnakeCase(fieldName string) string {
+	buf := bytes.NewBuffer([]byte{})
+	runes := []rune(fieldName)
+	for idx, r := range runes {
+		if unicode.IsUpper(r) {
+			if idx > 0 && (unicode.IsLower(runes[idx-1]) || (idx+1 < len(runes) && unicode.IsLower(runes[idx+1]))) {
+				buf.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// LowerCase converts a field name like FarmID to farmid
+func LowerCase(fieldName string) string {
+	return strings.ToLower(fieldName)
+}
+
+// CamelCase converts a field name like FarmID to farmId, the column is quoted in the select to keep the case
+func CamelCase(fieldName string) string {
+	parts := strings.Split(SnakeCase(fieldName), "_")
+	buf := bytes.NewBuffer([]byte{})
+	for idx, part := range parts {
+		if idx > 0 && len(part) > 0 {
+			runes := []rune(part)
+			runes[0] = unicode.ToUpper(runes[0])
+			part = string(runes)
+		}
+		buf.WriteString(part)
+	}
+	return buf.String()
+}
+
+// SetNamingStrategy sets the strategy that derives the column names of fields without column tag
+//
+// setting a strategy enables DeriveUntagged, explicit column tags always win over the strategy
+func (ctx *Api) SetNamingStrategy(strategy NamingStrategy) {
+	ctx.models.Lock()
+	defer ctx.models.Unlock()
+	if strategy == nil {
+		strategy = SnakeCase
+	}
+	ctx.models.options.naming = strategy
+	ctx.models.options.untagged = DeriveUntagged
+	ctx.models.invalidate(nil)
+}
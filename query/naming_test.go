@@ -0,0 +1,82 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type Untagged struct {
+	ID        int
+	FarmID    int
+	HTTPState string
+	Name      string `column:"tt.full_name"`
+}
+
+func (ctx Untagged) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.untagged"}, []string{"tt"}
+}
+
+func TestNamingStrategies(t *testing.T) {
+	for name, expected := range map[string][]string{
+		"FarmID":    {"farm_id", "farmid", "farmId"},
+		"HTTPState": {"http_state", "httpstate", "httpState"},
+		"Name":      {"name", "name", "name"},
+	} {
+		if SnakeCase(name) != expected[0] || LowerCase(name) != expected[1] || CamelCase(name) != expected[2] {
+			t.Errorf("unexpected names for %v: %v, %v, %v", name, SnakeCase(name), LowerCase(name), CamelCase(name))
+		}
+	}
+}
+
+func TestApi_SetNamingStrategy(t *testing.T) {
+	q := New(nil)
+	q.SetNamingStrategy(CamelCase)
+	query, err := q.generateSelect(Untagged{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if !strings.HasPrefix(query, "select tt.\"farmId\" as \"farmId\", tt.\"httpState\" as \"httpState\", tt.id as \"id\", tt.full_name as \"full_name\" from") {
+		t.Errorf("unexpected select %v", query)
+		return
+	}
+	info, err := q.GetModelInfo(Untagged{}, ColumnName)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if info["farmId"].FieldName != "FarmID" || info["full_name"].FieldName != "Name" {
+		t.Errorf("expect the result aliases to map back to the fields")
+	}
+
+	q.SetNamingStrategy(func(fieldName string) string {
+		return "c_" + LowerCase(fieldName)
+	})
+	query, _ = q.generateSelect(Untagged{}, "", -1, -1)
+	if !strings.HasPrefix(query, "select tt.c_farmid as \"c_farmid\"") {
+		t.Errorf("expect the custom strategy to be used but was %v", query)
+	}
+}
+
+type Sourced struct {
+	ID      int       `src:"x"`
+	Created time.Time `src:"x"`
+}
+
+func (ctx Sourced) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.x"}, []string{"x"}
+}
+
+func TestApi_SetNamingStrategyStructColumn(t *testing.T) {
+	q := New(nil)
+	q.SetNamingStrategy(SnakeCase)
+	query, err := q.generateSelect(Sourced{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if !strings.HasPrefix(query, "select x.created as \"created\", x.id as \"id\" from") {
+		t.Errorf("expect time.Time to be read from one column but was %v", query)
+	}
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// NullPolicy defines how NULL is read into and written from a field that can't hold nil, set it with the null tag
+//
+// pointer fields, sql.Null* types and Null[T] always keep NULL apart from the zero value
+type NullPolicy = string
+
+const (
+	// NullAsZero reads NULL as the zero value of the field and writes the zero value as it is, this is the default
+	NullAsZero NullPolicy = "zero"
+	// NullAsEmpty reads NULL as the zero value of the field and writes the zero value as NULL
+	NullAsEmpty NullPolicy = "empty"
+	// NullNotAllowed returns a ConversionError when the database returns NULL for the field
+	NullNotAllowed NullPolicy = "error"
+)
+
+// errNull is the error of a ConversionError for NULL in a field with NullNotAllowed
+var errNull = errors.New("NULL is not allowed, use a pointer, sql.Null* or Null[T] field")
+
+// Null holds a value of a column that can be NULL, Valid is false for NULL
+type Null[T any] struct {
+	Value T
+	Valid bool
+}
+
+// NewNull returns a valid Null with the value
+func NewNull[T any](value T) Null[T] {
+	return Null[T]{Value: value, Valid: true}
+}
+
+// FromDB reads the database value with the built-in conversion into T
+func (ctx *Null[T]) FromDB(value interface{}, c ConverterContext) error {
+	var zero T
+	ctx.Value, ctx.Valid = zero, false
+	if value == nil {
+		return nil
+	}
+	t := reflect.TypeOf(&zero).Elem()
+	var res interface{}
+	var err error
+	if jsonTarget(t) && t.Kind() != reflect.Slice {
+		res, err = decodeJSON(value, t)
+	} else {
+		res, err = convertValue(value, t)
+	}
+	if err == errUnsupported {
+		v, ok := value.(T)
+		if !ok {
+			return errors.New(fmt.Sprintf("can't convert %T to %v", value, t))
+		}
+		res, err = v, nil
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Value, ctx.Valid = res.(T), true
+	return nil
+}
+
+// ToDB writes NULL when the value is not valid
+func (ctx Null[T]) ToDB(c ConverterContext) (interface{}, error) {
+	if !ctx.Valid {
+		return nil, nil
+	}
+	return bindValue(ctx.Value), nil
+}
+
+// nullable checks if values of the type can be nil
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// validNullPolicy checks the value of the null tag
+func validNullPolicy(policy NullPolicy) bool {
+	switch policy {
+	case "", NullAsZero, NullAsEmpty, NullNotAllowed:
+		return true
+	}
+	return false
+}
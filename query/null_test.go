@@ -0,0 +1,116 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/mitchellh/mapstructure"
+	"testing"
+	"time"
+)
+
+type Harvest struct {
+	ID        int             `column:"id"`
+	Yield     *float64        `column:"yield"`
+	Harvested *time.Time      `column:"harvested"`
+	Note      string          `column:"note" null:"empty"`
+	Count     int             `column:"count"`
+	Moisture  sql.NullFloat64 `column:"moisture"`
+	Crop      Null[string]    `column:"crop"`
+	Organic   *bool           `column:"organic" read:"ReadBool" write:"WriteBool"`
+	Plot      Null[int32]     `column:"plot"`
+}
+
+func (ctx Harvest) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.harvests"}, []string{"h"}
+}
+
+type StrictHarvest struct {
+	ID    int `column:"id"`
+	Count int `column:"count" null:"error"`
+}
+
+func (ctx StrictHarvest) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.harvests"}, []string{"h"}
+}
+
+var harvestColumns = []string{"count", "crop", "harvested", "id", "moisture", "note", "organic", "plot", "yield"}
+var harvestTypes = []string{"int4", "text", "timestamptz", "int4", "float8", "text", "bool", "int4", "numeric"}
+
+func TestSelectNull(t *testing.T) {
+	harvested := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	q := newFakeApi(fakeResult{
+		columns: harvestColumns,
+		types:   harvestTypes,
+		rows: [][]driver.Value{
+			{nil, nil, nil, int64(1), nil, nil, nil, nil, nil},
+			{int64(0), []byte("wheat"), harvested, int64(2), float64(0.2), []byte(""), false, int64(7), []byte("0")},
+		},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Harvest{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Harvest
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	n := res[0]
+	if n.Yield != nil || n.Harvested != nil || n.Moisture.Valid || n.Crop.Valid || n.Organic != nil || n.Plot.Valid {
+		t.Errorf("expect NULL to be kept apart from zero values but was %v", n)
+	}
+	z := res[1]
+	if z.Yield == nil || *z.Yield != 0 || !z.Harvested.Equal(harvested) || !z.Moisture.Valid ||
+		z.Crop != NewNull("wheat") || z.Organic == nil || *z.Organic || z.Plot != NewNull(int32(7)) {
+		t.Errorf("expect zero values to be kept apart from NULL but was %v", z)
+	}
+
+	values, err := q.Values(n)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	for _, column := range []string{"yield", "harvested", "note", "moisture", "crop", "organic", "plot"} {
+		if v, ok := values[column]; !ok || v != nil {
+			t.Errorf("expect %v to be written as NULL but was %v", column, v)
+		}
+	}
+	if values["count"] != 0 {
+		t.Errorf("expect count to be written as 0 but was %v", values["count"])
+	}
+	values, err = q.Values(z)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["yield"] != float64(0) || values["crop"] != "wheat" || values["organic"] != false || values["plot"] != int32(7) {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestSelectNullNotAllowed(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"count", "id"},
+		types:   []string{"int4", "int4"},
+		rows:    [][]driver.Value{{nil, int64(1)}},
+	})
+	defer q.connection.Disconnect()
+	_, err := q.Select(StrictHarvest{}, "", -1, -1)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Field != "Count" || !errors.Is(err, errNull) {
+		t.Errorf("expect a NULL error of the field Count but was %v", err)
+	}
+}
+
+func TestNullPolicyTag(t *testing.T) {
+	type Invalid struct {
+		Count int `column:"count" null:"never"`
+	}
+	_, problems := readModelInfo(Invalid{}, defaultModelOptions)
+	if len(problems) != 1 || problems[0].Tag != "null" {
+		t.Errorf("expect a problem for the unknown null policy but was %v", problems)
+	}
+}
@@ -0,0 +1,110 @@
+package query
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"github.com/lib/pq"
+	"reflect"
+	"strconv"
+)
+
+// bindParameter replaces the named parameters (:name) of the query with positional placeholders ($1)
+// and returns the values in placeholder order
+//
+// names without a value in args, casts (::text) and quoted text are left untouched,
+// a name that is used more than once is bound to the same placeholder
+func bindParameter(query string, args map[string]interface{}) (string, []interface{}) {
+	if len(args) < 1 {
+		return query, nil
+	}
+	var values []interface{}
+	positions := make(map[string]int)
+	buf := bytes.NewBuffer([]byte{})
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			buf.WriteByte(ch)
+			continue
+		}
+		if ch == '\'' || ch == '"' {
+			quote = ch
+			buf.WriteByte(ch)
+			continue
+		}
+		if ch != ':' {
+			buf.WriteByte(ch)
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == ':' {
+			buf.WriteString("::")
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(query) && isParameterChar(query[end]) {
+			end++
+		}
+		name := query[i+1 : end]
+		value, ok := args[name]
+		if len(name) < 1 || !ok {
+			buf.WriteByte(ch)
+			continue
+		}
+		pos, ok := positions[name]
+		if !ok {
+			values = append(values, bindValue(value))
+			pos = len(values)
+			positions[name] = pos
+		}
+		buf.WriteString("$")
+		buf.WriteString(strconv.Itoa(pos))
+		i = end - 1
+	}
+	return buf.String(), values
+}
+
+func isParameterChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// bindValue prepares a parameter value for the driver, slices except bytes are sent as postgres arrays
+func bindValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return value
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are sent as bytea like ewkb of geometries
+			return v.Interface()
+		}
+		return pq.Array(v.Interface())
+	case reflect.Complex64:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 64)
+	case reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 128)
+	}
+	return v.Interface()
+}
+
+// bindArgs binds the optional parameter map of a select to the query
+func bindArgs(query string, args []map[string]interface{}) (string, []interface{}) {
+	if args != nil && len(args) > 0 {
+		return bindParameter(query, args[0])
+	}
+	return query, nil
+}
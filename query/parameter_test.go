@@ -0,0 +1,43 @@
+package query
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestBindParameter(t *testing.T) {
+	query, values := bindParameter("where id = :id and name = ':id' and age::text = :age and other = :id and x = :unknown", map[string]interface{}{
+		"id":  5,
+		"age": "' and 1 = 1",
+	})
+	if query != "where id = $1 and name = ':id' and age::text = $2 and other = $1 and x = :unknown" {
+		t.Errorf("unexpected query %v", query)
+		return
+	}
+	if len(values) != 2 || values[0] != 5 || values[1] != "' and 1 = 1" {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestBindValue(t *testing.T) {
+	for _, value := range []interface{}{
+		[]int{1, 2, 3},
+		&[]float64{1.5, 2.5},
+		[]string{"1", "a"},
+		[]time.Time{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		v, ok := bindValue(value).(driver.Valuer)
+		if !ok {
+			t.Errorf("expect %v to be bound as array", value)
+			continue
+		}
+		if _, err := v.Value(); err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+		}
+	}
+	var nilInt *int
+	if bindValue(nilInt) != nil {
+		t.Errorf("expect nil pointer to be bound as NULL")
+	}
+}
@@ -0,0 +1,146 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ConverterContext describes the field and column a converter is applied to
+type ConverterContext struct {
+	// Field the struct field of the model
+	Field reflect.StructField
+	// FieldName the path of the field in the model like Billing.Street
+	FieldName string
+	// Column the name of the result column or of the column that is written
+	Column string
+	// ColumnType the type of the result column, nil when writing
+	ColumnType *sql.ColumnType
+	// Args the arguments of the converter in the tag like keyA in read:"trim,decrypt(keyA)"
+	Args []string
+}
+
+// Converter converts a value read from the database into the field value or a field value into the value written to the database
+type Converter interface {
+	Convert(value interface{}, c ConverterContext) (interface{}, error)
+}
+
+// ConverterFunc is a function that can be used as Converter
+type ConverterFunc func(value interface{}, c ConverterContext) (interface{}, error)
+
+func (fn ConverterFunc) Convert(value interface{}, c ConverterContext) (interface{}, error) {
+	return fn(value, c)
+}
+
+// errNoValue is returned by a converter that did not produce a value for the field
+var errNoValue = errors.New("converter produced no value")
+
+// ConverterError is returned when a stage of a read or write pipeline fails
+type ConverterError struct {
+	Field     string
+	Tag       string
+	Stage     int
+	Converter string
+	Err       error
+}
+
+func (ctx *ConverterError) Error() string {
+	return fmt.Sprintf("error in converter %v (stage %v of %v tag) of field %v: %v", ctx.Converter, ctx.Stage, ctx.Tag, ctx.Field, ctx.Err.Error())
+}
+
+func (ctx *ConverterError) Unwrap() error {
+	return ctx.Err
+}
+
+// RegisterTypedConverter registers a converter that can be used with arguments and in chains of the read and write tag
+func (ctx *Api) RegisterTypedConverter(name string, converter Converter) {
+	ctx.converters[name] = converter
+}
+
+// AdaptConverter turns a ConverterFunction into a Converter, the value is taken from the result map
+// by field name or column name
+func AdaptConverter(converter ConverterFunction) Converter {
+	return ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		if len(c.Args) > 0 {
+			return nil, errors.New("converter takes no arguments")
+		}
+		res := make(map[string]interface{})
+		if err := converter(value, c.ColumnType, c.Field, c.Column, &res); err != nil {
+			return nil, err
+		}
+		if v, ok := res[c.Field.Name]; ok {
+			return v, nil
+		}
+		if v, ok := res[c.Column]; ok {
+			return v, nil
+		}
+		return nil, errNoValue
+	})
+}
+
+// newConverterContext returns the context of a converter for the field, typ is nil when writing
+func newConverterContext(info *ModelInfo, columnName string, typ *sql.ColumnType) ConverterContext {
+	return ConverterContext{
+		Field:      info.field,
+		FieldName:  info.FieldName,
+		Column:     columnName,
+		ColumnType: typ,
+	}
+}
+
+// runPipeline runs the converters of the read or write tag in order, each stage gets the output of the previous one
+//
+// the second result is false when a stage produced no value, the field is left out then
+func (ctx *Api) runPipeline(tag, chain string, value interface{}, c ConverterContext) (interface{}, bool, error) {
+	calls, err := parseConverterChain(chain, ",")
+	if err != nil {
+		return nil, false, &ConverterError{Field: c.FieldName, Tag: tag, Converter: chain, Err: err}
+	}
+	for idx, call := range calls {
+		conv, ok := ctx.converters[call.Name]
+		if !ok {
+			return nil, false, &ConverterError{Field: c.FieldName, Tag: tag, Stage: idx + 1, Converter: call.Name, Err: errors.New("converter is not registered")}
+		}
+		c.Args = call.Args
+		value, ok, err = runConverter(conv, value, c)
+		if err != nil {
+			return nil, false, &ConverterError{Field: c.FieldName, Tag: tag, Stage: idx + 1, Converter: call.Name, Err: err}
+		}
+		if !ok {
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// runConverter runs a single converter, the second result is false when the converter produced no value
+func runConverter(conv Converter, value interface{}, c ConverterContext) (interface{}, bool, error) {
+	value, err := conv.Convert(value, c)
+	if err == errNoValue {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// pipelineProblems checks that every converter of the chain is registered
+func (ctx *Api) pipelineProblems(tag, chain string, info *ModelInfo) []ModelProblem {
+	calls, err := parseConverterChain(chain, ",")
+	if err != nil {
+		return []ModelProblem{{Field: info.FieldName, Tag: tag, Message: err.Error()}}
+	}
+	var res []ModelProblem
+	for _, call := range calls {
+		if _, ok := ctx.converters[call.Name]; !ok {
+			res = append(res, ModelProblem{
+				Field:   info.FieldName,
+				Tag:     tag,
+				Message: fmt.Sprintf("converter %v is not registered", call.Name),
+			})
+		}
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Secret struct {
+	ID   int    `column:"id"`
+	Name string `column:"name" read:"trim,nullToEmpty,decrypt(keyA)" write:"encrypt(keyA),trim"`
+}
+
+func (ctx Secret) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.secrets"}, []string{"s"}
+}
+
+func registerSecretConverters(q *Api) {
+	q.RegisterTypedConverter("trim", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		switch v := value.(type) {
+		case []uint8:
+			return strings.TrimSpace(string(v)), nil
+		case string:
+			return strings.TrimSpace(v), nil
+		}
+		return value, nil
+	}))
+	q.RegisterTypedConverter("nullToEmpty", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		if value == nil {
+			return "", nil
+		}
+		return value, nil
+	}))
+	q.RegisterTypedConverter("decrypt", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		if len(c.Args) != 1 || c.Args[0] != "keyA" {
+			return nil, errors.New("unknown key")
+		}
+		return strings.TrimPrefix(value.(string), c.Args[0]+":"), nil
+	}))
+	q.RegisterTypedConverter("encrypt", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		return c.Args[0] + ":" + value.(string), nil
+	}))
+}
+
+func TestSelectConverterPipeline(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"id", "name"},
+		types:   []string{"int4", "text"},
+		rows:    [][]driver.Value{{int64(1), []byte(" keyA:secret ")}, {int64(2), nil}},
+	})
+	defer q.connection.Disconnect()
+	registerSecretConverters(q)
+	res, err := q.Select(Secret{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 2 || res[0]["Name"] != "secret" || res[1]["Name"] != "" {
+		t.Errorf("unexpected result %v", res)
+	}
+}
+
+func TestValuesConverterPipeline(t *testing.T) {
+	q := New(nil)
+	registerSecretConverters(q)
+	res, err := q.Values(Secret{ID: 1, Name: "secret "})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if res["name"] != "keyA:secret" {
+		t.Errorf("expect keyA:secret but was %v", res["name"])
+	}
+}
+
+func TestConverterPipelineStageError(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"id", "name"},
+		types:   []string{"int4", "text"},
+		rows:    [][]driver.Value{{int64(1), []byte("keyA:secret")}},
+	})
+	defer q.connection.Disconnect()
+	registerSecretConverters(q)
+	q.RegisterTypedConverter("decrypt", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		return nil, errors.New("bad key")
+	}))
+	_, err := q.Select(Secret{}, "", -1, -1)
+	var convErr *ConverterError
+	if !errors.As(err, &convErr) {
+		t.Errorf("expect a ConverterError but was %v", err)
+		return
+	}
+	if convErr.Field != "Name" || convErr.Stage != 3 || convErr.Converter != "decrypt" || convErr.Tag != "read" {
+		t.Errorf("unexpected error %v", convErr)
+	}
+
+	q.UnregisterConverter("nullToEmpty")
+	if err = q.RegisterModel(Secret{}); err == nil || !strings.Contains(err.Error(), "converter nullToEmpty is not registered") {
+		t.Errorf("expect a registration error for the missing stage but was %v", err)
+	}
+}
@@ -2,41 +2,60 @@ package query
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/nodejayes/qsm/connection"
 	"github.com/nodejayes/qsm/converter"
 	"reflect"
-	"sort"
 	"strconv"
 	"strings"
-	"time"
 )
 
 func New(connection *connection.Connection) *Api {
 	me := &Api{
-		connection:      connection,
-		converters:      make(map[string]ConverterFunction),
-		columnConverter: make(map[string]string),
+		connection:       connection,
+		converters:       make(map[string]Converter),
+		typeConverters:   make(map[reflect.Type]typeConverter),
+		dbTypeConverters: make(map[string]Converter),
+		columnConverter:  make(map[string]string),
+		cursorFetchSize:  DefaultCursorFetchSize,
+		statements:       newStatementCache(DefaultStatementCacheSize),
+		models:           newModelRegistry(),
+		unmapped:         StrictColumns,
 	}
 	me.RegisterConverter("ReadBool", converter.ReadBool)
 	me.RegisterConverter("WriteBool", converter.WriteBool)
 	return me
 }
 
+// ConverterFunction is the signature of the converters before Converter, it has to write the value into result
+// by field name or column name. use AdaptConverter or RegisterConverter to use it as Converter
 type ConverterFunction = func(dbValue interface{}, typ *sql.ColumnType, field reflect.StructField, columnName string, result *map[string]interface{}) error
 
 type Api struct {
-	connection      *connection.Connection
-	converters      map[string]ConverterFunction
-	columnConverter map[string]string
+	connection       *connection.Connection
+	converters       map[string]Converter
+	typeConverters   map[reflect.Type]typeConverter
+	dbTypeConverters map[string]Converter
+	columnConverter  map[string]string
+	cursorFetchSize  int
+	tx               *sql.Tx
+	statements       *statementCache
+	models           *modelRegistry
+	unmapped         UnmappedColumnPolicy
 }
 
 func (ctx *Api) RegisterConverter(name string, converter ConverterFunction) {
-	ctx.converters[name] = converter
+	ctx.converters[name] = AdaptConverter(converter)
 }
 
+// RegisterColumnConvert registers a sql expression that is applied to a column with name in the column tag
+//
+// $column is replaced with the column, $1, $2 ... with the arguments of the tag like tt.height->round(2).
+// numeric and boolean arguments are inserted as they are, other arguments as quoted text.
+// converters can be chained like tt.name->trim->lower and are applied from left to right
 func (ctx *Api) RegisterColumnConvert(name string, definition string) {
 	ctx.columnConverter[name] = definition
 }
@@ -45,75 +64,95 @@ func (ctx *Api) UnregisterConverter(name string) {
 	delete(ctx.converters, name)
 }
 
-func (ctx *Api) Select(target IModel, where string, limit, offset int, args ...map[string]interface{}) ([]map[string]interface{}, error) {
-	var res []map[string]interface{}
-	query := ctx.generateSelect(target, where, limit, offset)
-	if args != nil && len(args) > 0 {
-		query = ctx.replaceParameter(query, args[0])
-	}
+func (ctx *Api) UnregisterColumnConvert(name string) {
+	delete(ctx.columnConverter, name)
+}
 
-	if !ctx.connection.IsConnected() {
-		ctx.connection.Connect(50)
+func (ctx *Api) Select(target interface{}, where string, limit, offset int, args ...map[string]interface{}) ([]map[string]interface{}, error) {
+	query, err := ctx.generateSelect(target, where, limit, offset)
+	if err != nil {
+		return nil, err
 	}
-	rows, err := ctx.connection.GetInstance().Query(query)
-	if rows == nil {
-		return nil, errors.New("missing database rows instance")
+	query, values := bindArgs(query, args)
+	return ctx.selectQuery(target, query, values)
+}
+
+func (ctx *Api) selectQuery(target interface{}, query string, values []interface{}) ([]map[string]interface{}, error) {
+	rows, err := ctx.query(context.Background(), query, values)
+	if err != nil {
+		return nil, err
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	if err != nil {
-		return res, err
+	return ctx.fillResultRows(target, rows)
+}
+
+func (ctx *Api) db() *sql.DB {
+	if !ctx.connection.IsConnected() {
+		ctx.connection.Connect(50)
 	}
+	return ctx.connection.GetInstance()
+}
 
-	return ctx.fillResultRows(target, rows)
+func (ctx *Api) query(c context.Context, query string, values []interface{}) (*sql.Rows, error) {
+	db := ctx.db()
+	stmt, release, err := ctx.statements.get(c, db, query)
+	if err != nil {
+		return nil, wrapLockError(err)
+	}
+	// the statement may be closed by an eviction once the query started
+	defer release()
+	var rows *sql.Rows
+	switch {
+	case stmt != nil && ctx.tx != nil:
+		rows, err = ctx.tx.StmtContext(c, stmt).QueryContext(c, values...)
+	case stmt != nil:
+		rows, err = stmt.QueryContext(c, values...)
+	case ctx.tx != nil:
+		rows, err = ctx.tx.QueryContext(c, query, values...)
+	default:
+		rows, err = db.QueryContext(c, query, values...)
+	}
+	if err != nil {
+		return nil, wrapLockError(err)
+	}
+	if rows == nil {
+		return nil, errors.New("missing database rows instance")
+	}
+	return rows, nil
 }
 
-func (ctx *Api) generateSelect(target IModel, where string, limit, offset int) string {
-	info := GetModelInfo(target, FieldName)
-	types, sources, aliases := target.GetSources()
+func (ctx *Api) generateSelect(target interface{}, where string, limit, offset int) (string, error) {
+	m, err := ctx.model(target)
+	if err != nil {
+		return "", err
+	}
+	types, sources, aliases, err := ctx.sources(target)
+	if err != nil {
+		return "", err
+	}
+	aliasNames := sourceAliasNames(aliases)
 	buf := bytes.NewBuffer([]byte{})
 	buf.WriteString("select ")
 	counter := 0
 
-	infoKeys := make([]string, 0, len(info))
-	for k := range info {
-		infoKeys = append(infoKeys, k)
-	}
-	sort.Strings(infoKeys)
-
-	for _, k := range infoKeys {
-		infos := info[k]
-		columnName := infos.ColumnName
-		if strings.Contains(columnName, "->") {
-			tmpColumnInfos := strings.Split(columnName, "->")
-			if len(tmpColumnInfos) < 2 {
-				panic(fmt.Sprintf("column definition is wrong %v", columnName))
-			}
-			for key, def := range ctx.columnConverter {
-				if key == tmpColumnInfos[1] {
-					columnName = strings.ReplaceAll(def, "$column", tmpColumnInfos[0])
-					if len(infos.Alias) < 1 {
-						if strings.Contains(tmpColumnInfos[0], ".") {
-							infos.Alias = strings.Split(tmpColumnInfos[0], ".")[1]
-						} else {
-							infos.Alias = tmpColumnInfos[0]
-						}
-					}
-					break
-				}
-			}
+	for _, infos := range m.byField {
+		source := infos.Source
+		if len(source) > 0 && !containsString(aliasNames, source) {
+			return "", errors.New(fmt.Sprintf("source %v of field %v is not defined in GetSources of %v", source, infos.FieldName, reflect.TypeOf(target)))
 		}
-		if len(infos.Alias) < 1 {
-			if strings.Contains(columnName, ".") {
-				infos.Alias = strings.Split(columnName, ".")[1]
-			} else {
-				infos.Alias = columnName
-			}
+		if len(source) < 1 && len(aliasNames) == 1 && len(aliasNames[0]) > 0 {
+			// with a single source the column is unambiguous and can be qualified without a src tag
+			source = aliasNames[0]
 		}
-
-		columnName += fmt.Sprintf(" as \"%v\"", infos.Alias)
+		columnName, err := ctx.columnExpression(infos, infos.qualifiedColumn(source))
+		if err != nil {
+			return "", &ModelError{Model: m.typ.String(), Problems: []ModelProblem{{Field: infos.FieldName, Tag: "column", Message: err.Error()}}}
+		}
+		columnName = infos.readExpression(columnName, source)
+		columnName += fmt.Sprintf(" as \"%v\"", infos.ResultAlias)
 
 		if counter > 0 {
 			buf.WriteString(", ")
@@ -143,522 +182,128 @@ func (ctx *Api) generateSelect(target IModel, where string, limit, offset int) s
 		buf.WriteString(" offset ")
 		buf.WriteString(strconv.FormatInt(int64(offset), 10))
 	}
-	return buf.String()
+	return buf.String(), nil
 }
 
-func (ctx *Api) fillResultRows(target IModel, rows *sql.Rows) ([]map[string]interface{}, error) {
+func (ctx *Api) fillResultRows(target interface{}, rows *sql.Rows) ([]map[string]interface{}, error) {
 	var res []map[string]interface{}
-	infos := GetModelInfo(target, ColumnName)
-	s := reflect.TypeOf(target)
-	if s.Kind() == reflect.Ptr {
-		s = s.Elem()
+	m, err := ctx.model(target)
+	if err != nil {
+		return nil, err
 	}
 
-	for rows.Next() {
-		elem := make(map[string]interface{})
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
 
-		columns, err := rows.Columns()
-		if err != nil {
-			return nil, err
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		scanResult, scanErr := ctx.scanDbValues(rows, columns)
+		if scanErr != nil {
+			return nil, scanErr
 		}
 
-		types, err := rows.ColumnTypes()
+		elem, err := ctx.mapRow(m, columns, types, scanResult)
 		if err != nil {
 			return nil, err
 		}
+		res = append(res, elem)
+	}
+	return res, rows.Err()
+}
 
-		scanResult, scanErr := ctx.scanDbValues(rows, columns)
-		if scanErr != nil {
-			return nil, err
+func (ctx *Api) mapRow(m *model, columns []string, types []*sql.ColumnType, scanResult []interface{}) (map[string]interface{}, error) {
+	elem := make(map[string]interface{})
+	var extra map[string]interface{}
+	for idx := range columns {
+		info := m.column(columns[idx])
+		if info == nil {
+			switch {
+			case m.extra != nil:
+				if extra == nil {
+					extra = make(map[string]interface{})
+				}
+				if v, ok := scanResult[idx].([]uint8); ok {
+					extra[columns[idx]] = string(v)
+				} else {
+					extra[columns[idx]] = scanResult[idx]
+				}
+				break
+			case ctx.unmapped == LenientColumns:
+				break
+			default:
+				return nil, &UnmappedColumnError{Column: columns[idx], Model: m.typ.String()}
+			}
+			continue
 		}
 
-		for idx := range columns {
-			info := infos[columns[idx]]
-
-			f, ok := s.FieldByName(info.FieldName)
-			if !ok {
-				return nil, errors.New(fmt.Sprintf("can't get field info for field %v in struct %v", info.FieldName, s.Name()))
+		c := newConverterContext(info, columns[idx], types[idx])
+		if len(info.ReadConverter) < 1 {
+			if value, ok, err := scanField(scanResult[idx], c); ok {
+				if err != nil {
+					return nil, err
+				}
+				setFieldValue(elem, info.FieldName, value)
+				continue
 			}
-
-			conv := ctx.converters[info.ReadConverter]
-			if conv == nil {
-				switch v := scanResult[idx].(type) {
-				case []uint8:
-					if f.Type.Kind() == reflect.String {
-						elem[info.FieldName] = string(v)
-					}
-					break
-				default:
-					elem[info.FieldName] = scanResult[idx]
+			if conv, name := ctx.readConverter(info, types[idx].DatabaseTypeName()); conv != nil {
+				value, ok, err := runSelectedConverter("read", name, conv, scanResult[idx], c)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					setFieldValue(elem, info.FieldName, value)
 				}
 				continue
 			}
-			err := conv(scanResult[idx], types[idx], f, columns[idx], &elem)
+			value, ok, err := convertDbValue(scanResult[idx], types[idx].DatabaseTypeName(), info, columns[idx])
 			if err != nil {
-				return nil, errors.New(fmt.Sprintf("error in converter %v: %v", info.ReadConverter, err.Error()))
+				return nil, err
+			}
+			if ok {
+				setFieldValue(elem, info.FieldName, value)
 			}
-		}
-		res = append(res, elem)
-	}
-	return res, nil
-}
-
-func (ctx *Api) scanDbValues(rows *sql.Rows, columnNames []string) ([]interface{}, error) {
-	values := make([]interface{}, len(columnNames))
-	valuesPtr := make([]interface{}, len(columnNames))
-	for idx := range columnNames {
-		valuesPtr[idx] = &values[idx]
-	}
-	scanErr := rows.Scan(valuesPtr...)
-	return values, scanErr
-}
-
-func (ctx *Api) replaceParameter(query string, args map[string]interface{}) string {
-	for paramName, paramValue := range args {
-		if !strings.Contains(query, paramName) {
 			continue
 		}
-		query = strings.ReplaceAll(query, ":"+paramName, toSQLString(paramValue))
-	}
-	return query
-}
-
-func toSQLString(value interface{}) string {
-	switch v := value.(type) {
-	case bool:
-		if v {
-			return "true"
-		} else {
-			return "false"
-		}
-	case *bool:
-		if *v {
-			return "true"
-		} else {
-			return "false"
-		}
-	case int:
-		return strconv.FormatInt(int64(v), 10)
-	case *int:
-		return strconv.FormatInt(int64(*v), 10)
-	case int8:
-		return strconv.FormatInt(int64(v), 10)
-	case *int8:
-		return strconv.FormatInt(int64(*v), 10)
-	case int16:
-		return strconv.FormatInt(int64(v), 10)
-	case *int16:
-		return strconv.FormatInt(int64(*v), 10)
-	case int32:
-		return strconv.FormatInt(int64(v), 10)
-	case *int32:
-		return strconv.FormatInt(int64(*v), 10)
-	case int64:
-		return strconv.FormatInt(v, 10)
-	case *int64:
-		return strconv.FormatInt(*v, 10)
-	case uint:
-		return strconv.FormatUint(uint64(v), 10)
-	case *uint:
-		return strconv.FormatUint(uint64(*v), 10)
-	case uint8:
-		return strconv.FormatUint(uint64(v), 10)
-	case *uint8:
-		return strconv.FormatUint(uint64(*v), 10)
-	case uint16:
-		return strconv.FormatUint(uint64(v), 10)
-	case *uint16:
-		return strconv.FormatUint(uint64(*v), 10)
-	case uint32:
-		return strconv.FormatUint(uint64(v), 10)
-	case *uint32:
-		return strconv.FormatUint(uint64(*v), 10)
-	case uint64:
-		return strconv.FormatUint(v, 10)
-	case *uint64:
-		return strconv.FormatUint(*v, 10)
-	case float32:
-		return strconv.FormatFloat(float64(v), 'f', -1, 32)
-	case *float32:
-		return strconv.FormatFloat(float64(*v), 'f', -1, 32)
-	case float64:
-		return strconv.FormatFloat(v, 'f', -1, 64)
-	case *float64:
-		return strconv.FormatFloat(*v, 'f', -1, 64)
-	case complex64:
-		return strconv.FormatComplex(complex128(v), 'f', -1, 64)
-	case *complex64:
-		return strconv.FormatComplex(complex128(*v), 'f', -1, 128)
-	case complex128:
-		return strconv.FormatComplex(v, 'f', -1, 64)
-	case *complex128:
-		return strconv.FormatComplex(*v, 'f', -1, 64)
-	case string:
-		return fmt.Sprintf("'%v'", removeSqlInjections(v))
-	case *string:
-		return fmt.Sprintf("'%v'", removeSqlInjections(*v))
-	case time.Time:
-		return fmt.Sprintf("cast('%v' as timestamp)", v.Format(time.RFC3339))
-	case *time.Time:
-		return fmt.Sprintf("cast('%v' as timestamp)", (*v).Format(time.RFC3339))
-	case []int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16, []uint32, []uint64, []float32, []float64,
-		[]complex64, []complex128, []string, []time.Time:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(v))
-	case *[]int:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]int8:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]int16:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]int32:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]int64:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]uint:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]uint8:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]uint16:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]uint32:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]uint64:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]float32:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]float64:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]complex64:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]complex128:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]string:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	case *[]time.Time:
-		return fmt.Sprintf("ARRAY[%v]", buildArrayContent(*v))
-	default:
-		return fmt.Sprintf("%v", v)
-	}
-}
-
-func arrayInt(idsConverted []int) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatInt(int64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayInt8(idsConverted []int8) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatInt(int64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayInt16(idsConverted []int16) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatInt(int64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayInt32(idsConverted []int32) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatInt(int64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayInt64(idsConverted []int64) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatInt(id, 10))
-	}
-	return buf.String()
-}
-
-func arrayUint(idsConverted []uint) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatUint(uint64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayUint8(idsConverted []uint8) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatUint(uint64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayUint16(idsConverted []uint16) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatUint(uint64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayUint32(idsConverted []uint32) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatUint(uint64(id), 10))
-	}
-	return buf.String()
-}
-
-func arrayUint64(idsConverted []uint64) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatUint(id, 10))
-	}
-	return buf.String()
-}
-
-func arrayFloat32(idsConverted []float32) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
+		value, ok, err := ctx.runPipeline("read", info.ReadConverter, scanResult[idx], c)
+		if err != nil {
+			return nil, err
 		}
-		buf.WriteString(strconv.FormatFloat(float64(id), 'f', -1, 64))
-	}
-	return buf.String()
-}
-
-func arrayFloat64(idsConverted []float64) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
+		if ok {
+			setFieldValue(elem, info.FieldName, value)
 		}
-		buf.WriteString(strconv.FormatFloat(id, 'f', -1, 64))
 	}
-	return buf.String()
-}
-
-func arrayComplex64(idsConverted []complex64) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString(strconv.FormatComplex(complex128(id), 'f', -1, 64))
+	if extra != nil {
+		setFieldValue(elem, m.extra.FieldName, extra)
 	}
-	return buf.String()
+	return elem, nil
 }
 
-func arrayComplex128(idsConverted []complex128) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
+// setFieldValue stores the value of a field in the result row, fields of nested structs are stored in nested maps
+func setFieldValue(elem map[string]interface{}, fieldName string, value interface{}) {
+	path := strings.Split(fieldName, ".")
+	for _, name := range path[:len(path)-1] {
+		next, ok := elem[name].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			elem[name] = next
 		}
-		buf.WriteString(strconv.FormatComplex(id, 'f', -1, 128))
+		elem = next
 	}
-	return buf.String()
+	elem[path[len(path)-1]] = value
 }
 
-func arrayString(idsConverted []string) string {
-	buf := bytes.NewBuffer([]byte{})
-	for idx, id := range idsConverted {
-		if idx > 0 {
-			buf.WriteString(",")
-		}
-		buf.WriteString("'")
-		buf.WriteString(removeSqlInjections(id))
-		buf.WriteString("'")
-	}
-	return buf.String()
-}
-
-func buildArrayContent(values interface{}) string {
-	t := reflect.TypeOf(values)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	if t.Kind() != reflect.Slice {
-		return "ARRAY[]"
-	}
-
-	buf := bytes.NewBuffer([]byte{})
-	switch converted := values.(type) {
-	case []bool:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatBool(id))
-		}
-		break
-	case []int:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatInt(int64(id), 10))
-		}
-		break
-	case []int8:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatInt(int64(id), 10))
-		}
-		break
-	case []int16:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatInt(int64(id), 10))
-		}
-		break
-	case []int32:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatInt(int64(id), 10))
-		}
-		break
-	case []int64:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatInt(id, 10))
-		}
-		break
-	case []uint:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatUint(uint64(id), 10))
-		}
-		break
-	case []uint8:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatUint(uint64(id), 10))
-		}
-		break
-	case []uint16:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatUint(uint64(id), 10))
-		}
-		break
-	case []uint32:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatUint(uint64(id), 10))
-		}
-		break
-	case []uint64:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatUint(id, 10))
-		}
-		break
-	case []float32:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatFloat(float64(id), 'f', -1, 32))
-		}
-		break
-	case []float64:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatFloat(id, 'f', -1, 64))
-		}
-		break
-	case []complex64:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatComplex(complex128(id), 'f', -1, 64))
-		}
-		break
-	case []complex128:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(strconv.FormatComplex(id, 'f', -1, 128))
-		}
-		break
-	case []string:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(fmt.Sprintf("'%v'", removeSqlInjections(id)))
-		}
-		break
-	case []time.Time:
-		for idx, id := range converted {
-			if idx > 0 {
-				buf.WriteString(",")
-			}
-			buf.WriteString(fmt.Sprintf("cast('%v' as timestamp)", id.Format(time.RFC3339)))
-		}
-		break
+func (ctx *Api) scanDbValues(rows *sql.Rows, columnNames []string) ([]interface{}, error) {
+	values := make([]interface{}, len(columnNames))
+	valuesPtr := make([]interface{}, len(columnNames))
+	for idx := range columnNames {
+		valuesPtr[idx] = &values[idx]
 	}
-	return buf.String()
-}
-
-func removeSqlInjections(value string) string {
-	return strings.ReplaceAll(value, "'", "''")
+	scanErr := rows.Scan(valuesPtr...)
+	return values, scanErr
 }
@@ -95,3 +95,96 @@ func TestParameter(t *testing.T) {
 		"injection": "' and 1 = 1",
 	})
 }
+
+type DbReadModel struct {
+	ID   int    `column:"f.id"`
+	Geom string `column:"f.geom" dbread:"st_asgeojson(st_transform($column, 4326))"`
+	Area string `column:"f.geom" dbread:"st_area($alias.geom)" alias:"area"`
+}
+
+func (ctx DbReadModel) GetSources() ([]string, []string, []string) {
+	return []string{
+			"from",
+		}, []string{
+			"public.fields",
+		}, []string{
+			"f",
+		}
+}
+
+func TestGenerateSelectDbRead(t *testing.T) {
+	q := New(nil)
+	query, err := q.generateSelect(DbReadModel{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select st_area(f.geom) as \"area\", st_asgeojson(st_transform(f.geom, 4326)) as \"geom\", f.id as \"id\" from public.fields f "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+		return
+	}
+	infos := GetModelInfo(DbReadModel{}, ColumnName)
+	if infos["geom"].FieldName != "Geom" || infos["area"].FieldName != "Area" {
+		t.Errorf("expect the result aliases to map back to the fields")
+	}
+}
+
+func TestGenerateSelectSource(t *testing.T) {
+	q := New(nil)
+	query, err := q.generateSelect(FieldWithFarm{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select fa.id as \"fa_id\", fa.name as \"farm\", f.id as \"f_id\", f.name as \"name\" from public.fields f join public.farms fa on fa.id = f.farm_id "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+		return
+	}
+	_, err = q.generateSelect(&SampleField{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	_, err = q.generateSelect(UnknownSource{}, "", -1, -1)
+	if err == nil {
+		t.Errorf("expect an error for an unknown source alias")
+	}
+}
+
+type UnknownSource struct {
+	ID int `src:"x" column:"id"`
+}
+
+func (ctx UnknownSource) GetSources() ([]string, []string, []string) {
+	return []string{
+			"from",
+		}, []string{
+			"public.fields",
+		}, []string{
+			"f",
+		}
+}
+
+type ExpressionColumns struct {
+	Count int `column:"count(*)" alias:"cnt"`
+	Total int `column:"coalesce(tt.a, 0)" alias:"total"`
+}
+
+func (ctx ExpressionColumns) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.test_types"}, []string{"tt"}
+}
+
+func TestGenerateSelectExpressionColumns(t *testing.T) {
+	q := New(nil)
+	query, err := q.generateSelect(ExpressionColumns{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select count(*) as \"cnt\", coalesce(tt.a, 0) as \"total\" from public.test_types tt "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+	}
+}
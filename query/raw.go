@@ -0,0 +1,61 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+)
+
+// Query runs a hand written sql statement and maps the result columns onto the fields of target
+//
+// the columns are matched against the column, alias and read tags like in Select,
+// but target does not need to implement IModel. named parameters (:name) are bound from params
+func (ctx *Api) Query(sql string, params map[string]interface{}, target interface{}) ([]map[string]interface{}, error) {
+	if target == nil {
+		return nil, errors.New("missing target for query")
+	}
+	if _, err := ctx.model(target); err != nil {
+		return nil, err
+	}
+	query, values := bindParameter(sql, params)
+	return ctx.selectQuery(target, query, values)
+}
+
+// QueryInto works like Query and decodes the result into dest, dest must be a pointer to a slice of structs
+func (ctx *Api) QueryInto(sql string, params map[string]interface{}, dest interface{}) error {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf("dest must be a pointer to a slice but was %v", t))
+	}
+	elem := t.Elem().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return errors.New(fmt.Sprintf("dest must be a pointer to a slice of structs but was %v", t))
+	}
+
+	res, err := ctx.Query(sql, params, reflect.New(elem).Elem().Interface())
+	if err != nil {
+		return err
+	}
+	return mapstructure.Decode(res, dest)
+}
+
+// QueryRows works like Query but returns an iterator over the mapped rows
+func (ctx *Api) QueryRows(c context.Context, sql string, params map[string]interface{}, target interface{}) (*Rows, error) {
+	if target == nil {
+		return nil, errors.New("missing target for query")
+	}
+	if _, err := ctx.model(target); err != nil {
+		return nil, err
+	}
+	query, values := bindParameter(sql, params)
+	rows, err := ctx.query(c, query, values)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.newRows(c, target, rows)
+}
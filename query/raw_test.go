@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type Report struct {
+	Name   string `column:"name"`
+	Total  int64  `column:"total"`
+	Active bool   `column:"active" alias:"is_active" read:"ReadBool"`
+}
+
+func TestApi_QueryInto(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"name", "total", "is_active"},
+		types:   []string{"text", "int8", "bool"},
+		rows: [][]driver.Value{
+			{[]byte("a"), int64(5), true},
+			{[]byte("b"), int64(7), nil},
+		},
+	})
+	defer q.connection.Disconnect()
+	var res []Report
+	err := q.QueryInto("select name, count(*) as total, bool_or(active) as is_active from x where y > :y group by name", map[string]interface{}{
+		"y": 1,
+	}, &res)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 2 || res[0].Name != "a" || res[0].Total != 5 || !res[0].Active || res[1].Active {
+		t.Errorf("unexpected result %+v", res)
+		return
+	}
+	queries := fakeQueries()
+	if len(queries) != 1 || queries[0] != "select name, count(*) as total, bool_or(active) as is_active from x where y > $1 group by name" {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
+
+func TestApi_QueryIntoInvalidDest(t *testing.T) {
+	q := New(nil)
+	var res Report
+	if err := q.QueryInto("select 1", nil, &res); err == nil {
+		t.Errorf("expect an error for a dest that is not a slice")
+	}
+}
+
+func TestApi_QueryNoStruct(t *testing.T) {
+	q := New(nil)
+	for _, target := range []interface{}{map[string]interface{}{}, &[]Report{}, 5} {
+		if _, err := q.Query("select 1", nil, target); err == nil || !strings.Contains(err.Error(), "model must be a struct") {
+			t.Errorf("expect an error for target %T but was %v", target, err)
+		}
+		if _, err := q.QueryRows(context.Background(), "select 1", nil, target); err == nil || !strings.Contains(err.Error(), "model must be a struct") {
+			t.Errorf("expect an error for rows of target %T but was %v", target, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// model is the cached metadata of a model type
+type model struct {
+	typ   reflect.Type
+	infos []*ModelInfo
+	// byField the infos sorted by field name, the order of the generated select
+	byField []*ModelInfo
+	// byAlias maps the result alias of the select to the field
+	byAlias map[string]*ModelInfo
+	// byLowerAlias maps the lower case result alias to the field when it is unique
+	byLowerAlias map[string]*ModelInfo
+	// extra the catch-all field for unmapped result columns, nil when the model has none
+	extra *ModelInfo
+}
+
+type modelRegistry struct {
+	sync.RWMutex
+	options  modelOptions
+	models   map[reflect.Type]*model
+	mappings map[reflect.Type]*ModelMapping
+	// generation changes with every invalidation, a model read with older options is not cached
+	generation uint64
+}
+
+func newModelRegistry() *modelRegistry {
+	return &modelRegistry{
+		options:  defaultModelOptions,
+		models:   make(map[reflect.Type]*model),
+		mappings: make(map[reflect.Type]*ModelMapping),
+	}
+}
+
+// invalidate drops the cached model of t or all cached models when t is nil, the caller holds the write lock
+func (ctx *modelRegistry) invalidate(t reflect.Type) {
+	ctx.generation++
+	if t == nil {
+		ctx.models = make(map[reflect.Type]*model)
+		return
+	}
+	delete(ctx.models, t)
+}
+
+// SetUntaggedFieldPolicy defines how exported fields without a column tag are handled, the default is SkipUntagged
+func (ctx *Api) SetUntaggedFieldPolicy(policy UntaggedFieldPolicy) {
+	ctx.models.Lock()
+	defer ctx.models.Unlock()
+	ctx.models.options.untagged = policy
+	ctx.models.invalidate(nil)
+}
+
+// GetModelInfo works like the package function GetModelInfo but uses the options of the Api
+// and returns an error for an invalid model
+func (ctx *Api) GetModelInfo(target interface{}, master ModelInfoMapMaster) (map[string]*ModelInfo, error) {
+	m, err := ctx.model(target)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]*ModelInfo)
+	for _, info := range m.infos {
+		switch master {
+		case FieldName:
+			res[info.FieldName] = info
+			break
+		case ColumnName:
+			res[info.ResultAlias] = info
+			break
+		default:
+			return nil, errors.New(fmt.Sprintf("ModelInfoMapMaster %v not supported only use FieldName or ColumnName", master))
+		}
+	}
+	return res, nil
+}
+
+func modelType(target interface{}) reflect.Type {
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// model returns the metadata of the target type, it is read and checked once per type
+func (ctx *Api) model(target interface{}) (*model, error) {
+	if target == nil {
+		return nil, &ModelError{Model: "nil", Problems: []ModelProblem{{Message: "model is nil"}}}
+	}
+	t := modelType(target)
+	if t.Kind() != reflect.Struct {
+		return nil, &ModelError{Model: t.String(), Problems: []ModelProblem{{Message: "model must be a struct"}}}
+	}
+	ctx.models.RLock()
+	m, ok := ctx.models.models[t]
+	ctx.models.RUnlock()
+	if ok {
+		return m, nil
+	}
+	opts, generation := ctx.modelOptions(t)
+
+	infos, problems := readModelInfo(target, opts)
+	if len(problems) > 0 {
+		return nil, &ModelError{Model: t.String(), Problems: problems}
+	}
+	m = &model{
+		typ:          t,
+		byAlias:      make(map[string]*ModelInfo),
+		byLowerAlias: make(map[string]*ModelInfo),
+	}
+	ambiguous := make(map[string]bool)
+	for _, info := range infos {
+		if info.extra {
+			if m.extra != nil {
+				return nil, &ModelError{Model: t.String(), Problems: []ModelProblem{{
+					Field:   info.FieldName,
+					Tag:     "extra",
+					Message: fmt.Sprintf("field %v and %v are both tagged with extra", m.extra.FieldName, info.FieldName),
+				}}}
+			}
+			m.extra = info
+			continue
+		}
+		m.infos = append(m.infos, info)
+		m.byAlias[info.ResultAlias] = info
+		lower := strings.ToLower(info.ResultAlias)
+		if _, ok := m.byLowerAlias[lower]; ok {
+			ambiguous[lower] = true
+		}
+		m.byLowerAlias[lower] = info
+	}
+	for lower := range ambiguous {
+		delete(m.byLowerAlias, lower)
+	}
+	m.byField = append([]*ModelInfo{}, m.infos...)
+	sort.SliceStable(m.byField, func(i, j int) bool {
+		return m.byField[i].FieldName < m.byField[j].FieldName
+	})
+
+	ctx.models.Lock()
+	if ctx.models.generation == generation {
+		ctx.models.models[t] = m
+	}
+	ctx.models.Unlock()
+	return m, nil
+}
+
+// modelOptions returns the options to read the type with, including the tags of its mapping,
+// and the generation of the registry they belong to
+func (ctx *Api) modelOptions(t reflect.Type) (modelOptions, uint64) {
+	ctx.models.RLock()
+	defer ctx.models.RUnlock()
+	opts := ctx.models.options
+	if mapping, ok := ctx.models.mappings[t]; ok {
+		opts.tags = mapping.structTags()
+	}
+	return opts, ctx.models.generation
+}
+
+// column returns the field of a result column, a column that differs only in case is matched when it is unique
+func (ctx *model) column(name string) *ModelInfo {
+	if info, ok := ctx.byAlias[name]; ok {
+		return info
+	}
+	return ctx.byLowerAlias[strings.ToLower(name)]
+}
+
+// ModelProblem describes one mistake in the metadata of a model
+type ModelProblem struct {
+	// Field the path of the field, empty for problems of the whole model like GetSources
+	Field string
+	// Tag the tag that contains the mistake
+	Tag     string
+	Message string
+}
+
+func (ctx ModelProblem) String() string {
+	if len(ctx.Field) < 1 {
+		return ctx.Message
+	}
+	if len(ctx.Tag) < 1 {
+		return fmt.Sprintf("field %v: %v", ctx.Field, ctx.Message)
+	}
+	return fmt.Sprintf("field %v tag %v: %v", ctx.Field, ctx.Tag, ctx.Message)
+}
+
+// ModelError is returned when the metadata of a model is invalid
+type ModelError struct {
+	Model    string
+	Problems []ModelProblem
+}
+
+func (ctx *ModelError) Error() string {
+	problems := make([]string, len(ctx.Problems))
+	for idx, problem := range ctx.Problems {
+		problems[idx] = problem.String()
+	}
+	return "invalid model " + ctx.Model + ": " + strings.Join(problems, "; ")
+}
@@ -0,0 +1,175 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Rows iterates over the result of a model select and maps one row at a time
+//
+// use it instead of Select when the result does not fit into memory
+type Rows struct {
+	api     *Api
+	c       context.Context
+	rows    *sql.Rows
+	model   *model
+	columns []string
+	types   []*sql.ColumnType
+	current map[string]interface{}
+	err     error
+	closed  bool
+	cursor  *cursor
+	fetched int
+}
+
+// SelectRows runs the same query as Select but returns an iterator over the mapped rows
+//
+// the query is canceled and the iteration stops when c is done
+func (ctx *Api) SelectRows(c context.Context, target interface{}, where string, limit, offset int, args ...map[string]interface{}) (*Rows, error) {
+	query, err := ctx.generateSelect(target, where, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	query, values := bindArgs(query, args)
+	rows, err := ctx.query(c, query, values)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.newRows(c, target, rows)
+}
+
+func (ctx *Api) newRows(c context.Context, target interface{}, rows *sql.Rows) (*Rows, error) {
+	m, err := ctx.model(target)
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	res := &Rows{
+		api:   ctx,
+		c:     c,
+		rows:  rows,
+		model: m,
+	}
+	if err := res.readColumns(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+func (ctx *Rows) readColumns() error {
+	var err error
+	ctx.columns, err = ctx.rows.Columns()
+	if err != nil {
+		return err
+	}
+	ctx.types, err = ctx.rows.ColumnTypes()
+	return err
+}
+
+// Next prepares the next row for Row and Scan and returns false when there are no more rows or an error occurred
+func (ctx *Rows) Next() bool {
+	ctx.current = nil
+	if ctx.closed || ctx.err != nil {
+		return false
+	}
+	if ctx.err = ctx.c.Err(); ctx.err != nil {
+		_ = ctx.Close()
+		return false
+	}
+	if !ctx.rows.Next() && !ctx.fetchNext() {
+		_ = ctx.Close()
+		return false
+	}
+	ctx.fetched++
+
+	scanResult, err := ctx.api.scanDbValues(ctx.rows, ctx.columns)
+	if err == nil {
+		ctx.current, err = ctx.api.mapRow(ctx.model, ctx.columns, ctx.types, scanResult)
+	}
+	if err != nil {
+		ctx.err = err
+		_ = ctx.Close()
+		return false
+	}
+	return true
+}
+
+// fetchNext loads the next batch of a cursor and moves to its first row
+func (ctx *Rows) fetchNext() bool {
+	if ctx.err = ctx.rows.Err(); ctx.err != nil {
+		return false
+	}
+	if ctx.cursor == nil || ctx.fetched < ctx.cursor.fetchSize {
+		return false
+	}
+	if ctx.err = ctx.rows.Close(); ctx.err != nil {
+		return false
+	}
+	ctx.fetched = 0
+	ctx.rows, ctx.err = ctx.cursor.fetch(ctx.c)
+	if ctx.err != nil {
+		ctx.rows = nil
+		return false
+	}
+	if !ctx.rows.Next() {
+		ctx.err = ctx.rows.Err()
+		return false
+	}
+	return true
+}
+
+// Row returns the current row in the same format as one element of the Select result
+func (ctx *Rows) Row() map[string]interface{} {
+	return ctx.current
+}
+
+// Scan decodes the current row into dest, dest must be a pointer to the model struct
+func (ctx *Rows) Scan(dest interface{}) error {
+	if ctx.current == nil {
+		return errors.New("no current row, call Next before Scan")
+	}
+	return mapstructure.Decode(ctx.current, dest)
+}
+
+// Err returns the error that stopped the iteration, nil when all rows were read
+func (ctx *Rows) Err() error {
+	return ctx.err
+}
+
+// Close releases the underlying database rows, it is safe to call Close more than once
+//
+// for a cursor the cursor is closed and the transaction ended as well
+func (ctx *Rows) Close() error {
+	if ctx.closed {
+		return nil
+	}
+	ctx.closed = true
+	var err error
+	if ctx.rows != nil {
+		err = ctx.rows.Close()
+	}
+	if ctx.cursor != nil {
+		if cursorErr := ctx.cursor.close(ctx.c, ctx.err == nil); err == nil {
+			err = cursorErr
+		}
+	}
+	return err
+}
+
+// ForEach calls fn for every remaining row and closes the Rows afterwards
+//
+// the iteration stops at the first error returned by fn
+func (ctx *Rows) ForEach(fn func(row map[string]interface{}) error) error {
+	defer func() {
+		_ = ctx.Close()
+	}()
+	for ctx.Next() {
+		if err := fn(ctx.current); err != nil {
+			return err
+		}
+	}
+	return ctx.err
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestRows_ForEach(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("a")}, {[]byte("b")}, {[]byte("c")}},
+	})
+	defer q.connection.Disconnect()
+	rows, err := q.SelectRows(context.Background(), Db{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var versions []string
+	err = rows.ForEach(func(row map[string]interface{}) error {
+		versions = append(versions, row["Version"].(string))
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(versions) != 3 || versions[0] != "a" || versions[2] != "c" {
+		t.Errorf("expect versions a, b, c but was %v", versions)
+		return
+	}
+	if rows.Next() {
+		t.Errorf("expect Next to return false after ForEach")
+	}
+}
+
+func TestRows_Scan(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("PostgreSQL 12")}},
+	})
+	defer q.connection.Disconnect()
+	rows, err := q.SelectRows(context.Background(), Db{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var res Db
+	if rows.Next() {
+		err = rows.Scan(&res)
+	}
+	if err != nil || res.Version != "PostgreSQL 12" {
+		t.Errorf("expect version to be scanned but was %v (%v)", res.Version, err)
+	}
+}
+
+func TestRows_ContextCanceled(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("a")}, {[]byte("b")}},
+	})
+	defer q.connection.Disconnect()
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows, err := q.SelectRows(c, Db{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	count := 0
+	for rows.Next() {
+		count++
+		cancel()
+	}
+	if count != 1 {
+		t.Errorf("expect iteration to stop after cancel but read %v rows", count)
+	}
+	if rows.Err() != context.Canceled {
+		t.Errorf("expect context.Canceled but was %v", rows.Err())
+	}
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
+// FromDB is implemented by field types that read themselves from the database value
+//
+// it is used ahead of sql.Scanner, the converters selected by type and the built-in conversion
+type FromDB interface {
+	FromDB(value interface{}, c ConverterContext) error
+}
+
+// ToDB is implemented by field types that return the value written to the database
+//
+// it is used ahead of driver.Valuer, the converters selected by type and the built-in conversion
+type ToDB interface {
+	ToDB(c ConverterContext) (interface{}, error)
+}
+
+var (
+	fromDBType  = reflect.TypeOf((*FromDB)(nil)).Elem()
+	toDBType    = reflect.TypeOf((*ToDB)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// scanField reads the value with FromDB or sql.Scanner when the field type implements one of them
+//
+// the second result is false when the field type implements neither, NULL leaves pointer fields nil
+func scanField(value interface{}, c ConverterContext) (interface{}, bool, error) {
+	t := c.Field.Type
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	ptrType := reflect.PtrTo(t)
+	if !ptrType.Implements(fromDBType) && !ptrType.Implements(scannerType) {
+		return nil, false, nil
+	}
+	if value == nil && isPtr {
+		return nil, true, nil
+	}
+	res := reflect.New(t)
+	var err error
+	if fromDB, ok := res.Interface().(FromDB); ok {
+		err = fromDB.FromDB(value, c)
+	} else {
+		err = res.Interface().(sql.Scanner).Scan(value)
+	}
+	if err != nil {
+		return nil, true, &ConverterError{Field: c.FieldName, Tag: "read", Stage: 1, Converter: scanInterfaceName(ptrType), Err: err}
+	}
+	if isPtr {
+		return res.Interface(), true, nil
+	}
+	return res.Elem().Interface(), true, nil
+}
+
+func scanInterfaceName(t reflect.Type) string {
+	if t.Implements(fromDBType) {
+		return "FromDB"
+	}
+	return "Scan"
+}
+
+// valueField returns the value of ToDB or driver.Valuer when the field type implements one of them
+//
+// the second result is false when the field type implements neither, nil pointers are written as NULL
+func valueField(v reflect.Value, c ConverterContext) (interface{}, bool, error) {
+	t := v.Type()
+	if !t.Implements(toDBType) && !t.Implements(valuerType) {
+		if t.Kind() == reflect.Ptr || (!reflect.PtrTo(t).Implements(toDBType) && !reflect.PtrTo(t).Implements(valuerType)) {
+			return nil, false, nil
+		}
+		// the method has a pointer receiver, call it on a copy
+		ptr := reflect.New(t)
+		ptr.Elem().Set(v)
+		v = ptr
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, true, nil
+	}
+	var res interface{}
+	var err error
+	name := "ToDB"
+	if toDB, ok := v.Interface().(ToDB); ok {
+		res, err = toDB.ToDB(c)
+	} else {
+		name = "Value"
+		res, err = v.Interface().(driver.Valuer).Value()
+	}
+	if err != nil {
+		return nil, true, &ConverterError{Field: c.FieldName, Tag: "write", Stage: 1, Converter: name, Err: err}
+	}
+	return res, true, nil
+}
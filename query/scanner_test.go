@@ -0,0 +1,147 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/mitchellh/mapstructure"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Cents implements sql.Scanner and driver.Valuer
+type Cents int64
+
+func (ctx *Cents) Scan(value interface{}) error {
+	text, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprintf("unexpected money value %v", value))
+	}
+	f, err := strconv.ParseFloat(strings.TrimPrefix(string(text), "$"), 64)
+	if err != nil {
+		return err
+	}
+	*ctx = Cents(f * 100)
+	return nil
+}
+
+func (ctx Cents) Value() (driver.Value, error) {
+	return fmt.Sprintf("%.2f", float64(ctx)/100), nil
+}
+
+// Code implements FromDB and ToDB
+type Code struct {
+	Prefix string
+	Number int
+}
+
+func (ctx *Code) FromDB(value interface{}, c ConverterContext) error {
+	parts := strings.SplitN(string(value.([]byte)), "-", 2)
+	if len(parts) != 2 {
+		return errors.New("invalid code")
+	}
+	n, err := strconv.Atoi(parts[1])
+	ctx.Prefix, ctx.Number = parts[0], n
+	return err
+}
+
+func (ctx Code) ToDB(c ConverterContext) (interface{}, error) {
+	return fmt.Sprintf("%v-%v", ctx.Prefix, ctx.Number), nil
+}
+
+type Order struct {
+	ID      int            `column:"id"`
+	Price   Cents          `column:"price"`
+	Code    Code           `column:"code"`
+	Comment sql.NullString `column:"comment"`
+	Shipped pq.NullTime    `column:"shipped"`
+	Ref     *Code          `column:"ref"`
+}
+
+func (ctx Order) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.orders"}, []string{"o"}
+}
+
+func TestSelectScanner(t *testing.T) {
+	shipped := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	q := newFakeApi(fakeResult{
+		columns: []string{"code", "comment", "id", "price", "ref", "shipped"},
+		types:   []string{"text", "text", "int4", "money", "text", "timestamptz"},
+		rows: [][]driver.Value{
+			{[]byte("A-12"), nil, int64(1), []byte("$12.50"), nil, shipped},
+			{[]byte("B-3"), []byte("fragile"), int64(2), []byte("$1.00"), []byte("A-12"), nil},
+		},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Order{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Order
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if res[0].Price != 1250 || res[0].Code != (Code{"A", 12}) || res[0].Comment.Valid || res[0].Ref != nil ||
+		!res[0].Shipped.Valid || !res[0].Shipped.Time.Equal(shipped) {
+		t.Errorf("unexpected first row %v", res[0])
+	}
+	if res[1].Comment.String != "fragile" || res[1].Shipped.Valid || res[1].Ref == nil || *res[1].Ref != (Code{"A", 12}) {
+		t.Errorf("unexpected second row %v", res[1])
+	}
+
+	values, err := q.Values(res[0])
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["price"] != "12.50" || values["code"] != "A-12" || values["comment"] != nil || values["ref"] != nil ||
+		values["shipped"] != shipped {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestSelectScannerError(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"code", "id"},
+		types:   []string{"text", "int4"},
+		rows:    [][]driver.Value{{[]byte("A12"), int64(1)}},
+	})
+	defer q.connection.Disconnect()
+	_, err := q.Select(Order{}, "", -1, -1)
+	var convErr *ConverterError
+	if !errors.As(err, &convErr) || convErr.Field != "Code" || convErr.Converter != "FromDB" {
+		t.Errorf("expect a FromDB error of the field Code but was %v", err)
+	}
+}
+
+type SourcedScanner struct {
+	ID      int            `src:"x"`
+	Name    sql.NullString `src:"x"`
+	Shipped pq.NullTime    `src:"x"`
+	Code    Code           `src:"x"`
+	Crop    Null[string]   `src:"x"`
+}
+
+func (ctx SourcedScanner) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.x"}, []string{"x"}
+}
+
+func TestGenerateSelectScannerColumns(t *testing.T) {
+	q := New(nil)
+	q.SetNamingStrategy(SnakeCase)
+	query, err := q.generateSelect(SourcedScanner{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := "select x.code as \"code\", x.crop as \"crop\", x.id as \"id\", x.name as \"name\", x.shipped as \"shipped\" from public.x x "
+	if query != expected {
+		t.Errorf("expect %v but was %v", expected, query)
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// DefaultStatementCacheSize is the number of prepared statements an Api keeps by default
+const DefaultStatementCacheSize = 100
+
+// StatementCacheStats describes the usage of the prepared statement cache
+type StatementCacheStats struct {
+	Size      int
+	Limit     int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type statementCache struct {
+	sync.Mutex
+	db        *sql.DB
+	limit     int
+	order     *list.List
+	items     map[string]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type statementCacheEntry struct {
+	query string
+	stmt  *sql.Stmt
+	// refs the number of queries that got the statement and did not start yet
+	refs int
+	// evicted is set when the entry left the cache, the statement is closed when refs drops to 0
+	evicted bool
+}
+
+func newStatementCache(limit int) *statementCache {
+	return &statementCache{
+		limit: limit,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// SetStatementCacheSize sets the number of prepared statements that are kept for the generated queries
+//
+// the least recently used statement is closed when the limit is reached,
+// a size below 1 disables the cache, use this behind transaction pooling proxies like pgbouncer
+func (ctx *Api) SetStatementCacheSize(size int) {
+	ctx.statements.Lock()
+	defer ctx.statements.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	ctx.statements.limit = size
+	for ctx.statements.order.Len() > size {
+		ctx.statements.evictOldest()
+	}
+}
+
+// StatementCacheStats returns the current size, limit and hit statistics of the prepared statement cache
+func (ctx *Api) StatementCacheStats() StatementCacheStats {
+	ctx.statements.Lock()
+	defer ctx.statements.Unlock()
+	return StatementCacheStats{
+		Size:      ctx.statements.order.Len(),
+		Limit:     ctx.statements.limit,
+		Hits:      ctx.statements.hits,
+		Misses:    ctx.statements.misses,
+		Evictions: ctx.statements.evictions,
+	}
+}
+
+// ClearStatementCache closes all cached prepared statements
+func (ctx *Api) ClearStatementCache() {
+	ctx.statements.Lock()
+	defer ctx.statements.Unlock()
+	ctx.statements.clear()
+}
+
+// get returns the prepared statement for query, nil when the cache is disabled
+//
+// release must be called once the query was started, an evicted statement is closed after its last user released it.
+// the statement is prepared outside the lock so a slow prepare does not block the other queries
+func (ctx *statementCache) get(c context.Context, db *sql.DB, query string) (*sql.Stmt, func(), error) {
+	ctx.Lock()
+	if ctx.limit < 1 {
+		ctx.Unlock()
+		return nil, func() {}, nil
+	}
+	if ctx.db != db {
+		// the connection was reopened, statements of the old connection are useless
+		ctx.clear()
+		ctx.db = db
+	}
+	if elem, ok := ctx.items[query]; ok {
+		ctx.hits++
+		ctx.order.MoveToFront(elem)
+		entry := elem.Value.(*statementCacheEntry)
+		entry.refs++
+		ctx.Unlock()
+		return entry.stmt, ctx.releaser(entry), nil
+	}
+	ctx.misses++
+	ctx.Unlock()
+
+	stmt, err := db.PrepareContext(c, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx.Lock()
+	defer ctx.Unlock()
+	if ctx.limit < 1 || ctx.db != db {
+		// the cache was disabled or reset meanwhile, the statement is only used for this query
+		return stmt, func() {
+			_ = stmt.Close()
+		}, nil
+	}
+	if elem, ok := ctx.items[query]; ok {
+		// another query prepared the same statement meanwhile
+		_ = stmt.Close()
+		ctx.order.MoveToFront(elem)
+		entry := elem.Value.(*statementCacheEntry)
+		entry.refs++
+		return entry.stmt, ctx.releaser(entry), nil
+	}
+	entry := &statementCacheEntry{
+		query: query,
+		stmt:  stmt,
+		refs:  1,
+	}
+	ctx.items[query] = ctx.order.PushFront(entry)
+	for ctx.order.Len() > ctx.limit {
+		ctx.evictOldest()
+	}
+	return stmt, ctx.releaser(entry), nil
+}
+
+// releaser returns the function that ends the use of the entry, it may be called only once
+func (ctx *statementCache) releaser(entry *statementCacheEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ctx.Lock()
+			defer ctx.Unlock()
+			entry.refs--
+			if entry.evicted && entry.refs == 0 {
+				_ = entry.stmt.Close()
+			}
+		})
+	}
+}
+
+func (ctx *statementCache) evictOldest() {
+	elem := ctx.order.Back()
+	if elem == nil {
+		return
+	}
+	entry := ctx.order.Remove(elem).(*statementCacheEntry)
+	delete(ctx.items, entry.query)
+	ctx.remove(entry)
+	ctx.evictions++
+}
+
+// remove closes the statement of the entry or marks it to be closed by the last user
+//
+// once a query was started database/sql keeps the statement open until its rows are closed
+func (ctx *statementCache) remove(entry *statementCacheEntry) {
+	entry.evicted = true
+	if entry.refs == 0 {
+		_ = entry.stmt.Close()
+	}
+}
+
+func (ctx *statementCache) clear() {
+	for _, elem := range ctx.items {
+		ctx.remove(elem.Value.(*statementCacheEntry))
+	}
+	ctx.order.Init()
+	ctx.items = make(map[string]*list.Element)
+}
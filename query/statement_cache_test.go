@@ -0,0 +1,79 @@
+package query
+
+import (
+	"database/sql/driver"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func versionResult() fakeResult {
+	return fakeResult{
+		columns: []string{"version"},
+		types:   []string{"text"},
+		rows:    [][]driver.Value{{[]byte("a")}},
+	}
+}
+
+func TestApi_StatementCache(t *testing.T) {
+	q := newFakeApi(versionResult(), versionResult(), versionResult())
+	defer q.connection.Disconnect()
+	q.SetStatementCacheSize(1)
+	for _, where := range []string{"where 1 = :one", "where 1 = :one", "where 2 = :one"} {
+		if _, err := q.Select(Db{}, where, -1, -1, map[string]interface{}{"one": 1}); err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+			return
+		}
+	}
+	stats := q.StatementCacheStats()
+	if stats.Size != 1 || stats.Limit != 1 || stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 1 {
+		t.Errorf("unexpected statistics %+v", stats)
+	}
+}
+
+func TestApi_StatementCacheDisabled(t *testing.T) {
+	q := newFakeApi(versionResult(), versionResult())
+	defer q.connection.Disconnect()
+	q.SetStatementCacheSize(0)
+	for i := 0; i < 2; i++ {
+		if _, err := q.Select(Db{}, "", -1, -1); err != nil {
+			t.Errorf("expect err to be nil but was: %v", err.Error())
+			return
+		}
+	}
+	stats := q.StatementCacheStats()
+	if stats.Size != 0 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("expect the cache not to be used but was %+v", stats)
+	}
+}
+
+func TestApi_StatementCacheConcurrentEviction(t *testing.T) {
+	q := newFakeApi()
+	defer q.connection.Disconnect()
+	q.SetStatementCacheSize(1)
+	// the eviction race only shows up with goroutines running in parallel
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	wheres := []string{"where 1 = :one", "where 2 = :one", "where 3 = :one", "where 4 = :one", "where 5 = :one"}
+	errs := make(chan error, 8*200)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if _, err := q.Select(Db{}, wheres[(i+j)%len(wheres)], -1, -1, map[string]interface{}{"one": 1}); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if stats := q.StatementCacheStats(); stats.Size != 1 {
+		t.Errorf("unexpected statistics %+v", stats)
+	}
+}
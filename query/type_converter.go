@@ -0,0 +1,82 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// typeConverter holds the converters that are applied to all fields of one Go type
+type typeConverter struct {
+	read  Converter
+	write Converter
+}
+
+// RegisterTypeConverter registers converters that are applied to every field of type T without read or write tag
+//
+// read converts the database value into T and write converts T into the value written to the database,
+// one of them can be nil
+func RegisterTypeConverter[T any](api *Api, read func(value interface{}, c ConverterContext) (T, error), write func(value T, c ConverterContext) (interface{}, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	conv := typeConverter{}
+	if read != nil {
+		conv.read = ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+			return read(value, c)
+		})
+	}
+	if write != nil {
+		conv.write = ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+			v, ok := value.(T)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("can't convert %v to %v", reflect.TypeOf(value), t))
+			}
+			return write(v, c)
+		})
+	}
+	api.typeConverters[t] = conv
+}
+
+// UnregisterTypeConverter removes the converters of the Go type T
+func UnregisterTypeConverter[T any](api *Api) {
+	delete(api.typeConverters, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// RegisterDbTypeConverter registers a read converter that is applied to every result column with the
+// database type name like JSONB or NUMERIC when the field has no read tag and no converter for its Go type
+func (ctx *Api) RegisterDbTypeConverter(dbType string, converter Converter) {
+	ctx.dbTypeConverters[strings.ToUpper(dbType)] = converter
+}
+
+// UnregisterDbTypeConverter removes the read converter of the database type name
+func (ctx *Api) UnregisterDbTypeConverter(dbType string) {
+	delete(ctx.dbTypeConverters, strings.ToUpper(dbType))
+}
+
+// readConverter selects the converter for a field without read tag by its Go type and then by the database type
+func (ctx *Api) readConverter(info *ModelInfo, dbType string) (Converter, string) {
+	if conv := ctx.typeConverters[info.field.Type].read; conv != nil {
+		return conv, info.field.Type.String()
+	}
+	if conv, ok := ctx.dbTypeConverters[strings.ToUpper(dbType)]; ok {
+		return conv, strings.ToUpper(dbType)
+	}
+	return nil, ""
+}
+
+// writeConverter selects the converter for a field without write tag by its Go type
+func (ctx *Api) writeConverter(info *ModelInfo) (Converter, string) {
+	if conv := ctx.typeConverters[info.field.Type].write; conv != nil {
+		return conv, info.field.Type.String()
+	}
+	return nil, ""
+}
+
+// runSelectedConverter runs a converter selected by type and reports errors like a single stage pipeline
+func runSelectedConverter(tag, name string, conv Converter, value interface{}, c ConverterContext) (interface{}, bool, error) {
+	value, ok, err := runConverter(conv, value, c)
+	if err != nil {
+		return nil, false, &ConverterError{Field: c.FieldName, Tag: tag, Stage: 1, Converter: name, Err: err}
+	}
+	return value, ok, nil
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/nodejayes/qsm/converter"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Money int64
+
+type Invoice struct {
+	ID     int    `column:"id"`
+	Amount Money  `column:"amount"`
+	Note   string `column:"note"`
+}
+
+func (ctx Invoice) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.invoices"}, []string{"i"}
+}
+
+func registerMoneyConverter(q *Api) {
+	RegisterTypeConverter(q, func(value interface{}, c ConverterContext) (Money, error) {
+		f, err := strconv.ParseFloat(string(value.([]uint8)), 64)
+		if err != nil {
+			return 0, err
+		}
+		return Money(f * 100), nil
+	}, func(value Money, c ConverterContext) (interface{}, error) {
+		return strconv.FormatFloat(float64(value)/100, 'f', 2, 64), nil
+	})
+}
+
+func TestSelectTypeConverter(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"amount", "id", "note"},
+		types:   []string{"numeric", "int4", "text"},
+		rows:    [][]driver.Value{{[]byte("12.50"), int64(1), []byte("first")}},
+	})
+	defer q.connection.Disconnect()
+	registerMoneyConverter(q)
+	q.RegisterDbTypeConverter("text", ConverterFunc(func(value interface{}, c ConverterContext) (interface{}, error) {
+		return strings.ToUpper(string(value.([]uint8))), nil
+	}))
+	res, err := q.Select(Invoice{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 1 || res[0]["Amount"] != Money(1250) || res[0]["Note"] != "FIRST" {
+		t.Errorf("unexpected result %v", res)
+	}
+
+	values, err := q.Values(Invoice{ID: 1, Amount: 1250, Note: "first"})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["amount"] != "12.50" || values["note"] != "first" {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestTypeConverterError(t *testing.T) {
+	q := newFakeApi(fakeResult{
+		columns: []string{"amount", "id", "note"},
+		types:   []string{"numeric", "int4", "text"},
+		rows:    [][]driver.Value{{[]byte("abc"), int64(1), []byte("first")}},
+	})
+	defer q.connection.Disconnect()
+	registerMoneyConverter(q)
+	_, err := q.Select(Invoice{}, "", -1, -1)
+	var convErr *ConverterError
+	if !errors.As(err, &convErr) || convErr.Field != "Amount" || convErr.Converter != "query.Money" {
+		t.Errorf("expect a ConverterError of the field Amount but was %v", err)
+	}
+
+	UnregisterTypeConverter[Money](q)
+	if _, ok := q.typeConverters[reflect.TypeOf(Money(0))]; ok {
+		t.Errorf("expect the type converter to be removed")
+	}
+}
+
+func TestAdaptConverter(t *testing.T) {
+	field, _ := reflect.TypeOf(Customer{}).FieldByName("Active")
+	read := AdaptConverter(converter.ReadBool)
+	v, err := read.Convert(true, ConverterContext{Field: field, FieldName: "Active", Column: "active"})
+	if err != nil || v != true {
+		t.Errorf("expect true but was %v %v", v, err)
+	}
+	write := AdaptConverter(converter.WriteBool)
+	v, err = write.Convert(false, ConverterContext{Field: field, FieldName: "Active", Column: "active"})
+	if err != nil || v != false {
+		t.Errorf("expect false but was %v %v", v, err)
+	}
+	if _, err = read.Convert(true, ConverterContext{Field: field, Args: []string{"1"}}); err == nil {
+		t.Errorf("expect an error for arguments of an adapted converter")
+	}
+}
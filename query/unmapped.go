@@ -0,0 +1,30 @@
+package query
+
+import "fmt"
+
+// UnmappedColumnPolicy defines how result columns are handled that no field of the model is mapped to
+//
+// a field of type map[string]interface{} tagged with extra collects these columns regardless of the policy
+type UnmappedColumnPolicy = string
+
+const (
+	// StrictColumns returns an UnmappedColumnError for a result column without field
+	StrictColumns UnmappedColumnPolicy = "strict"
+	// LenientColumns ignores result columns without field
+	LenientColumns UnmappedColumnPolicy = "lenient"
+)
+
+// UnmappedColumnError is returned in StrictColumns mode when a result column can't be mapped to a field
+type UnmappedColumnError struct {
+	Column string
+	Model  string
+}
+
+func (ctx *UnmappedColumnError) Error() string {
+	return fmt.Sprintf("result column %v is not mapped to a field of %v", ctx.Column, ctx.Model)
+}
+
+// SetUnmappedColumnPolicy defines how result columns without field are handled, the default is StrictColumns
+func (ctx *Api) SetUnmappedColumnPolicy(policy UnmappedColumnPolicy) {
+	ctx.unmapped = policy
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/mitchellh/mapstructure"
+	"testing"
+)
+
+type ReportWithExtra struct {
+	Name  string                 `column:"name"`
+	Extra map[string]interface{} `extra:""`
+}
+
+func unmappedResult() fakeResult {
+	return fakeResult{
+		columns: []string{"NAME", "total", "ratio"},
+		types:   []string{"text", "int8", "float8"},
+		rows:    [][]driver.Value{{[]byte("a"), int64(5), 0.5}},
+	}
+}
+
+func TestUnmappedColumnStrict(t *testing.T) {
+	q := newFakeApi(unmappedResult())
+	defer q.connection.Disconnect()
+	_, err := q.Query("select * from report", nil, Report{})
+	var unmappedErr *UnmappedColumnError
+	if !errors.As(err, &unmappedErr) || unmappedErr.Column != "ratio" {
+		t.Errorf("expect an UnmappedColumnError for column ratio but was %v", err)
+	}
+}
+
+func TestUnmappedColumnLenient(t *testing.T) {
+	q := newFakeApi(unmappedResult())
+	defer q.connection.Disconnect()
+	q.SetUnmappedColumnPolicy(LenientColumns)
+	var res []Report
+	err := q.QueryInto("select * from report", nil, &res)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 1 || res[0].Name != "a" || res[0].Total != 5 {
+		t.Errorf("expect the mapped columns to be read but was %+v", res)
+	}
+}
+
+func TestUnmappedColumnExtra(t *testing.T) {
+	q := newFakeApi(unmappedResult())
+	defer q.connection.Disconnect()
+	tmp, err := q.Query("select * from report", nil, ReportWithExtra{})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []ReportWithExtra
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 1 || res[0].Name != "a" || res[0].Extra["total"] != int64(5) || res[0].Extra["ratio"] != 0.5 {
+		t.Errorf("expect the unmapped columns in the extra field but was %+v", res)
+	}
+}
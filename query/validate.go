@@ -0,0 +1,114 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegistrationError is returned by RegisterModel and contains the problems of all invalid models
+type RegistrationError struct {
+	Models []*ModelError
+}
+
+func (ctx *RegistrationError) Error() string {
+	models := make([]string, len(ctx.Models))
+	for idx, m := range ctx.Models {
+		models[idx] = m.Error()
+	}
+	return strings.Join(models, "\n")
+}
+
+// RegisterModel validates the models up front so mistakes in tags and GetSources are found at startup
+//
+// it checks the sources, the converter names of the read, write and column tags, the aliases and the field types.
+// register the converters before the models, all problems are returned in one RegistrationError
+func (ctx *Api) RegisterModel(models ...interface{}) error {
+	var res []*ModelError
+	for _, target := range models {
+		if err := ctx.validateModel(target); err != nil {
+			res = append(res, err)
+		}
+	}
+	if len(res) > 0 {
+		return &RegistrationError{Models: res}
+	}
+	return nil
+}
+
+func (ctx *Api) validateModel(target interface{}) *ModelError {
+	if target == nil {
+		return &ModelError{Model: "nil", Problems: []ModelProblem{{Message: "model is nil"}}}
+	}
+	t := modelType(target)
+	if t.Kind() != reflect.Struct {
+		return &ModelError{Model: t.String(), Problems: []ModelProblem{{Message: "model must be a struct"}}}
+	}
+	opts, _ := ctx.modelOptions(t)
+	infos, problems := readModelInfo(target, opts)
+	_, sources, aliases, err := ctx.sources(target)
+	if err != nil {
+		problems = append(problems, ModelProblem{Message: err.Error()})
+	} else if len(sources) < 1 {
+		problems = append(problems, ModelProblem{Message: "GetSources returns no source"})
+	}
+	aliasNames := sourceAliasNames(aliases)
+
+	for _, info := range infos {
+		if info.extra {
+			continue
+		}
+		if err == nil && len(info.Source) > 0 && !containsString(aliasNames, info.Source) {
+			problems = append(problems, ModelProblem{
+				Field:   info.FieldName,
+				Tag:     "src",
+				Message: fmt.Sprintf("source %v is not defined in GetSources", info.Source),
+			})
+		}
+		if _, err := ctx.columnExpression(info, info.Name); err != nil {
+			problems = append(problems, ModelProblem{
+				Field:   info.FieldName,
+				Tag:     "column",
+				Message: err.Error(),
+			})
+		}
+		if len(info.ReadConverter) > 0 {
+			problems = append(problems, ctx.pipelineProblems("read", info.ReadConverter, info)...)
+		}
+		if len(info.WriteConverter) > 0 {
+			problems = append(problems, ctx.pipelineProblems("write", info.WriteConverter, info)...)
+		}
+		if len(info.ReadConverter) < 1 && ctx.typeConverters[info.field.Type].read == nil && !supportedFieldType(info.field.Type) {
+			problems = append(problems, ModelProblem{
+				Field:   info.FieldName,
+				Message: fmt.Sprintf("field type %v is not supported, use a read converter", info.field.Type),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ModelError{Model: t.String(), Problems: problems}
+	}
+	if _, err := ctx.model(target); err != nil {
+		if modelErr, ok := err.(*ModelError); ok {
+			return modelErr
+		}
+		return &ModelError{Model: t.String(), Problems: []ModelProblem{{Message: err.Error()}}}
+	}
+	return nil
+}
+
+// supportedFieldType checks if values of the type can be read from the database without converter
+func supportedFieldType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type BrokenModel struct {
+	ID       int              `src:"b" column:"id" alias:"ident"`
+	Name     string           `src:"x" column:"name"`
+	Age      int              `column:"b.age->unknownColumn"`
+	Active   bool             `column:"b.active" read:"unknownRead" write:"WriteBool"`
+	Callback func()           `column:"b.callback"`
+	Complex  complex128       `column:"b.complex" alias:"ident"`
+	Channel  chan interface{} `column:"b.channel" read:"ReadBool"`
+}
+
+func (ctx BrokenModel) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.broken"}, []string{"b"}
+}
+
+type BrokenSources struct {
+	ID int `column:"id"`
+}
+
+func (ctx BrokenSources) GetSources() ([]string, []string, []string) {
+	return []string{"from", "join"}, []string{"public.a"}, []string{"a"}
+}
+
+func TestApi_RegisterModel(t *testing.T) {
+	q := New(nil)
+	err := q.RegisterModel(TestTypes{}, Db{})
+	if err == nil || !strings.Contains(err.Error(), "column converter addOneConverter is not registered") {
+		t.Errorf("expect the missing column converter of TestTypes but was %v", err)
+		return
+	}
+	q.RegisterColumnConvert("addOneConverter", "$column + 1")
+	if err = q.RegisterModel(TestTypes{}, Db{}, &SampleField{}); err == nil ||
+		!strings.Contains(err.Error(), "field Geom tag read: converter geoJsonRead is not registered") {
+		t.Errorf("expect only the converters of SampleField to be missing but was %v", err)
+		return
+	}
+	if err = q.RegisterModel(TestTypes{}, Db{}); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+	}
+}
+
+func TestApi_RegisterModelProblems(t *testing.T) {
+	q := New(nil)
+	err := q.RegisterModel(BrokenModel{}, BrokenSources{})
+	var registrationErr *RegistrationError
+	if !errors.As(err, &registrationErr) || len(registrationErr.Models) != 2 {
+		t.Errorf("expect a RegistrationError for both models but was %v", err)
+		return
+	}
+	if !strings.Contains(registrationErr.Models[1].Error(), "GetSources of query.BrokenSources returns 2 types, 1 sources and 1 aliases") {
+		t.Errorf("expect the source lengths to be reported but was %v", registrationErr.Models[1])
+	}
+	for _, expected := range []string{
+		"field Complex tag alias: alias ident is used by field ID and Complex",
+		"field Name tag src: source x is not defined in GetSources",
+		"field Age tag column: column converter unknownColumn is not registered",
+		"field Active tag read: converter unknownRead is not registered",
+		"field Callback: field type func() is not supported",
+		"field Complex: field type complex128 is not supported",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expect %v in %v", expected, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "Channel") {
+		t.Errorf("expect a field with read converter to be accepted")
+	}
+}
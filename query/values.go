@@ -0,0 +1,89 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Values returns the field values of target keyed by their column name and applies the write converters
+//
+// the column names are not qualified with the source alias so they can be used in insert and update statements,
+// fields of a nested struct pointer that is nil are left out
+func (ctx *Api) Values(target interface{}) (map[string]interface{}, error) {
+	m, err := ctx.model(target)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.Indirect(reflect.ValueOf(target))
+	res := make(map[string]interface{})
+	for _, info := range m.infos {
+		if len(info.Name) < 1 {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(v, info.Index)
+		if !ok {
+			continue
+		}
+		value, ok, err := ctx.writeValue(info, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res[info.Name] = value
+		}
+	}
+	return res, nil
+}
+
+// writeValue converts the field value with the write tag, ToDB or driver.Valuer, the converter of the field type,
+// json or array encoding in this order, the second result is false when the field is left out
+func (ctx *Api) writeValue(info *ModelInfo, fieldValue reflect.Value) (interface{}, bool, error) {
+	c := newConverterContext(info, info.Name, nil)
+	if len(info.WriteConverter) > 0 {
+		return ctx.runPipeline("write", info.WriteConverter, fieldValue.Interface(), c)
+	}
+	if value, ok, err := valueField(fieldValue, c); ok {
+		return value, true, err
+	}
+	if fieldValue.Kind() == reflect.Ptr || fieldValue.Kind() == reflect.Interface {
+		if fieldValue.IsNil() {
+			return nil, true, nil
+		}
+	}
+	if info.Null == NullAsEmpty && fieldValue.IsZero() {
+		return nil, true, nil
+	}
+	if conv, name := ctx.writeConverter(info); conv != nil {
+		return runSelectedConverter("write", name, conv, fieldValue.Interface(), c)
+	}
+	if writesJSON(info) {
+		value, err := encodeJSON(fieldValue)
+		if err != nil {
+			return nil, false, errors.New(fmt.Sprintf("can't write field %v as json: %v", info.FieldName, err.Error()))
+		}
+		return value, true, nil
+	}
+	if isArrayType(fieldValue.Type()) {
+		value, err := encodeArray(fieldValue)
+		if err != nil {
+			return nil, false, errors.New(fmt.Sprintf("can't write field %v as array: %v", info.FieldName, err.Error()))
+		}
+		return value, true, nil
+	}
+	return reflect.Indirect(fieldValue).Interface(), true, nil
+}
+
+// fieldByIndex works like reflect FieldByIndex but returns false instead of panic on a nil struct pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"database/sql/driver"
+	"github.com/mitchellh/mapstructure"
+	"testing"
+	"time"
+)
+
+type Audit struct {
+	CreatedAt time.Time `column:"created_at"`
+	UpdatedBy string    `column:"updated_by"`
+}
+
+type Address struct {
+	Street string `column:"street"`
+	City   string `column:"city"`
+}
+
+type Customer struct {
+	Audit
+	ID       int      `column:"id"`
+	Billing  Address  `prefix:"billing_"`
+	Shipping *Address `prefix:"shipping_"`
+	Active   bool     `column:"active" read:"ReadBool" write:"WriteBool"`
+}
+
+func (ctx Customer) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.customers"}, []string{"c"}
+}
+
+func TestGetModelInfoNested(t *testing.T) {
+	info := GetModelInfo(Customer{}, FieldName)
+	if info["Audit.CreatedAt"].Name != "created_at" ||
+		info["Billing.Street"].Name != "billing_street" ||
+		info["Shipping.City"].Name != "shipping_city" {
+		t.Errorf("expect nested fields to be flattened with their column prefix")
+	}
+	if len(info["Shipping.City"].Index) != 2 || info["Shipping.City"].Index[0] != 3 {
+		t.Errorf("expect the index path of the nested field")
+	}
+}
+
+func TestSelectNested(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := newFakeApi(fakeResult{
+		columns: []string{"active", "created_at", "updated_by", "billing_city", "billing_street", "id", "shipping_city", "shipping_street"},
+		types:   []string{"bool", "timestamp", "text", "text", "text", "int4", "text", "text"},
+		rows: [][]driver.Value{
+			{true, created, []byte("me"), []byte("Berlin"), []byte("Main St"), int64(1), []byte("Hamburg"), []byte("Dock St")},
+		},
+	})
+	defer q.connection.Disconnect()
+	tmp, err := q.Select(Customer{}, "", -1, -1)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	var res []Customer
+	if err = mapstructure.Decode(tmp, &res); err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if len(res) != 1 || !res[0].CreatedAt.Equal(created) || res[0].UpdatedBy != "me" || !res[0].Active ||
+		res[0].Billing.City != "Berlin" || res[0].Shipping == nil || res[0].Shipping.Street != "Dock St" {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestApi_Values(t *testing.T) {
+	q := New(nil)
+	values, err := q.Values(&Customer{
+		Audit:   Audit{UpdatedBy: "me"},
+		ID:      5,
+		Billing: Address{City: "Berlin"},
+		Active:  true,
+	})
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	if values["id"] != 5 || values["updated_by"] != "me" || values["billing_city"] != "Berlin" || values["active"] != true {
+		t.Errorf("unexpected values %v", values)
+	}
+	if _, ok := values["shipping_city"]; ok {
+		t.Errorf("expect the fields of a nil nested pointer to be left out")
+	}
+}
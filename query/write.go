@@ -0,0 +1,50 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WriteColumn is a column of an insert or update statement with the sql expression of its value
+type WriteColumn struct {
+	// Name the plain column name
+	Name string
+	// Expression the placeholder like $1 or the dbwrite template with $value replaced by the placeholder
+	Expression string
+	// Value the value to bind to the placeholder after the write converters
+	Value interface{}
+}
+
+// WriteColumns returns the columns of Values sorted by name with the sql expressions and values to bind
+//
+// the placeholders are numbered from start so they can follow the parameters of a where clause
+func (ctx *Api) WriteColumns(target interface{}, start int) ([]WriteColumn, error) {
+	m, err := ctx.model(target)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ctx.Values(target)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]*ModelInfo)
+	for _, info := range m.infos {
+		if len(info.Name) > 0 {
+			infos[info.Name] = info
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]WriteColumn, len(names))
+	for idx, name := range names {
+		res[idx] = WriteColumn{
+			Name:       name,
+			Expression: infos[name].writeExpression(fmt.Sprintf("$%v", start+idx)),
+			Value:      bindValue(values[name]),
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"testing"
+)
+
+type Plot struct {
+	ID   int    `column:"id"`
+	Geom string `column:"geom" dbwrite:"st_geomfromgeojson($value)"`
+	Name string `column:"name" dbwrite:"upper('$value')"`
+	Raw  []byte `column:"raw"`
+}
+
+func (ctx Plot) GetSources() ([]string, []string, []string) {
+	return []string{"from"}, []string{"public.plots"}, []string{"p"}
+}
+
+func TestWriteColumns(t *testing.T) {
+	q := New(nil)
+	geom := `{"type":"Point","coordinates":[1,2]}`
+	res, err := q.WriteColumns(Plot{ID: 1, Geom: geom, Name: "north", Raw: []byte{1}}, 2)
+	if err != nil {
+		t.Errorf("expect err to be nil but was: %v", err.Error())
+		return
+	}
+	expected := []WriteColumn{
+		{Name: "geom", Expression: "st_geomfromgeojson($2)", Value: geom},
+		{Name: "id", Expression: "$3", Value: 1},
+		{Name: "name", Expression: "upper($4)", Value: "north"},
+		{Name: "raw", Expression: "$5"},
+	}
+	if len(res) != len(expected) {
+		t.Errorf("expect %v columns but was %v", len(expected), res)
+		return
+	}
+	for idx, c := range expected {
+		if res[idx].Name != c.Name || res[idx].Expression != c.Expression || (c.Value != nil && res[idx].Value != c.Value) {
+			t.Errorf("expect %v but was %v", c, res[idx])
+		}
+	}
+	if raw, ok := res[3].Value.([]byte); !ok || raw[0] != 1 {
+		t.Errorf("expect the bytes to be bound as they are but was %v", res[3].Value)
+	}
+}